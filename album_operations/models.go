@@ -0,0 +1,32 @@
+package album_operations
+
+import "time"
+
+type Options struct {
+	// Only compute target albums without creating or changing anything
+	DryRun bool
+	// Skip media items that are already in target album. Matters only when target album exists
+	Deduplicate bool
+	// Set cover photo of target album to cover photo of source album when it's one of target items
+	CopyCover bool
+}
+
+type SplitOptions struct {
+	Options
+	// Time zone used to determine month of media item. Defaults to time.Local
+	Location *time.Location
+	// Returns title of album for month. Defaults to "<album title> - 2006-01"
+	TitleFormat func(albumTitle string, month time.Time) string
+}
+
+// Album created or updated by operation
+type TargetAlbum struct {
+	// Empty in dry run mode when album would be created
+	AlbumId string
+	Title   string
+	// Media items added (or to be added in dry run mode) to album
+	MediaItemIds          []string
+	CoverPhotoMediaItemId string
+	// Album didn't exist before operation
+	Created bool
+}
@@ -0,0 +1,314 @@
+package album_operations
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"time"
+)
+
+// High-level album operations. Membership is read with SearchAllAsync and written with BatchAddMediaItemsAll
+type AlbumOperations struct {
+	albums     albums.AlbumsService
+	mediaItems media_items.MediaItemsService
+}
+
+func (o AlbumOperations) albumItems(albumId string, ctx context.Context) ([]media_items.MediaItem, error) {
+	itemsC, errorsC := o.mediaItems.SearchAllAsync(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, ctx)
+	result := make([]media_items.MediaItem, 0)
+	for {
+		select {
+		case item, ok := <-itemsC:
+			if !ok {
+				return result, ctx.Err()
+			}
+			result = append(result, item)
+		case err := <-errorsC:
+			return nil, fmt.Errorf("cannot list items of album '%s': %w", albumId, err)
+		}
+	}
+}
+
+func (o AlbumOperations) albumItemIds(albumId string, ctx context.Context) ([]string, error) {
+	items, err := o.albumItems(albumId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.ID)
+	}
+	return result, nil
+}
+
+// Albums are listed once so targets of one operation don't list them again
+func (o AlbumOperations) albumsByTitle(ctx context.Context) (map[string][]albums.Album, error) {
+	allAlbums, err := o.albums.ListAll(nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list albums: %w", err)
+	}
+	result := make(map[string][]albums.Album)
+	for _, album := range allAlbums {
+		result[album.Title] = append(result[album.Title], album)
+	}
+	return result, nil
+}
+
+// Writes to existing album instead of creating new one. Media items that are already in album are skipped when
+// Deduplicate is set
+func (o AlbumOperations) useExisting(target *TargetAlbum, existing albums.Album, options Options, ctx context.Context) error {
+	target.AlbumId = existing.ID
+	target.Created = false
+	if !options.Deduplicate {
+		return nil
+	}
+	existingIds, err := o.albumItemIds(existing.ID, ctx)
+	if err != nil {
+		return err
+	}
+	skip := idSet(existingIds)
+	pending := make([]string, 0, len(target.MediaItemIds))
+	for _, id := range target.MediaItemIds {
+		if _, ok := skip[id]; !ok {
+			pending = append(pending, id)
+		}
+	}
+	target.MediaItemIds = pending
+	return nil
+}
+
+func idSet(ids []string) map[string]struct{} {
+	result := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		result[id] = struct{}{}
+	}
+	return result
+}
+
+// Returns album that should be reused for target title. Albums can share titles, picking one of them could write to
+// the wrong album so ambiguous title is an error
+func existingAlbum(existing []albums.Album, title string) (*albums.Album, error) {
+	switch len(existing) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &existing[0], nil
+	}
+	return nil, fmt.Errorf("multiple albums titled '%s'", title)
+}
+
+func contains(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates album (unless it exists already), adds media items and sets cover photo
+func (o AlbumOperations) write(target *TargetAlbum, options Options, ctx context.Context) error {
+	if options.DryRun {
+		return nil
+	}
+	if target.AlbumId == "" {
+		album, err := o.albums.Create(target.Title, ctx)
+		if err != nil {
+			return fmt.Errorf("cannot create album '%s': %w", target.Title, err)
+		}
+		target.AlbumId = album.ID
+	}
+	_, err := o.albums.BatchAddMediaItemsAll(target.AlbumId, target.MediaItemIds, nil, ctx)
+	if err != nil {
+		return fmt.Errorf("cannot add items to album '%s': %w", target.Title, err)
+	}
+	if target.CoverPhotoMediaItemId == "" {
+		return nil
+	}
+	_, err = o.albums.Patch(albums.Album{
+		ID:                    target.AlbumId,
+		CoverPhotoMediaItemID: target.CoverPhotoMediaItemId,
+	}, []albums.Field{albums.AlbumFieldCoverPhotoMediaItemId}, ctx)
+	if err != nil {
+		return fmt.Errorf("cannot set cover photo of album '%s': %w", target.Title, err)
+	}
+	return nil
+}
+
+// Merges items of source albums (in source order) into album with target title. Existing album with that title is
+// used when it exists, merge fails when there are more of them. Cover photo of first source album is copied when
+// CopyCover is set
+func (o AlbumOperations) Merge(sourceAlbumIds []string, targetTitle string, options *Options, ctx context.Context) (*TargetAlbum, error) {
+	mergeOptions := Options{}
+	if options != nil {
+		mergeOptions = *options
+	}
+	target := &TargetAlbum{
+		Title:        targetTitle,
+		MediaItemIds: make([]string, 0),
+		Created:      true,
+	}
+	found, err := o.albums.FindByTitle(targetTitle, nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find target album: %w", err)
+	}
+	existing, err := existingAlbum(found, targetTitle)
+	if err != nil {
+		return nil, fmt.Errorf("cannot merge albums: %w", err)
+	}
+	skip := make(map[string]struct{})
+	if existing != nil {
+		target.AlbumId = existing.ID
+		target.Created = false
+		if mergeOptions.Deduplicate {
+			existingIds, err := o.albumItemIds(target.AlbumId, ctx)
+			if err != nil {
+				return nil, err
+			}
+			skip = idSet(existingIds)
+		}
+	}
+	for i, albumId := range sourceAlbumIds {
+		ids, err := o.albumItemIds(albumId, ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if _, ok := skip[id]; ok {
+				continue
+			}
+			skip[id] = struct{}{}
+			target.MediaItemIds = append(target.MediaItemIds, id)
+		}
+		if i == 0 && mergeOptions.CopyCover {
+			source, err := o.albums.Get(albumId, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get source album: %w", err)
+			}
+			if contains(ids, source.CoverPhotoMediaItemID) {
+				target.CoverPhotoMediaItemId = source.CoverPhotoMediaItemID
+			}
+		}
+	}
+	err = o.write(target, mergeOptions, ctx)
+	if err != nil {
+		return target, fmt.Errorf("cannot merge albums: %w", err)
+	}
+	return target, nil
+}
+
+func defaultSplitTitleFormat(albumTitle string, month time.Time) string {
+	return albumTitle + " - " + month.Format("2006-01")
+}
+
+// Splits album into albums, one per month of media items creation time. Existing albums with target titles are
+// reused so split can be run again after new items are added to source album, split fails before writing anything
+// when any title is ambiguous. Source album is not changed
+func (o AlbumOperations) SplitByMonth(albumId string, options *SplitOptions, ctx context.Context) ([]TargetAlbum, error) {
+	splitOptions := SplitOptions{}
+	if options != nil {
+		splitOptions = *options
+	}
+	if splitOptions.Location == nil {
+		splitOptions.Location = time.Local
+	}
+	if splitOptions.TitleFormat == nil {
+		splitOptions.TitleFormat = defaultSplitTitleFormat
+	}
+	source, err := o.albums.Get(albumId, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get album: %w", err)
+	}
+	items, err := o.albumItems(albumId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]TargetAlbum, 0)
+	monthIndexes := make(map[string]int)
+	for _, item := range items {
+		creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+		}
+		localTime := creationTime.In(splitOptions.Location)
+		month := time.Date(localTime.Year(), localTime.Month(), 1, 0, 0, 0, 0, splitOptions.Location)
+		monthKey := month.Format("2006-01")
+		index, ok := monthIndexes[monthKey]
+		if !ok {
+			index = len(result)
+			monthIndexes[monthKey] = index
+			result = append(result, TargetAlbum{
+				Title:        splitOptions.TitleFormat(source.Title, month),
+				MediaItemIds: make([]string, 0),
+				Created:      true,
+			})
+		}
+		result[index].MediaItemIds = append(result[index].MediaItemIds, item.ID)
+		if splitOptions.CopyCover && item.ID == source.CoverPhotoMediaItemID {
+			result[index].CoverPhotoMediaItemId = item.ID
+		}
+	}
+	existingAlbums, err := o.albumsByTitle(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		existing, err := existingAlbum(existingAlbums[result[i].Title], result[i].Title)
+		if err != nil {
+			return nil, fmt.Errorf("cannot split album: %w", err)
+		}
+		if existing != nil {
+			err = o.useExisting(&result[i], *existing, splitOptions.Options, ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i := range result {
+		err = o.write(&result[i], splitOptions.Options, ctx)
+		if err != nil {
+			return result, fmt.Errorf("cannot split album: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// Creates new album with the same media items (in the same order) as source album
+func (o AlbumOperations) Clone(albumId string, title string, options *Options, ctx context.Context) (*TargetAlbum, error) {
+	cloneOptions := Options{}
+	if options != nil {
+		cloneOptions = *options
+	}
+	source, err := o.albums.Get(albumId, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get album: %w", err)
+	}
+	ids, err := o.albumItemIds(albumId, ctx)
+	if err != nil {
+		return nil, err
+	}
+	target := &TargetAlbum{
+		Title:        title,
+		MediaItemIds: ids,
+		Created:      true,
+	}
+	if cloneOptions.CopyCover && contains(ids, source.CoverPhotoMediaItemID) {
+		target.CoverPhotoMediaItemId = source.CoverPhotoMediaItemID
+	}
+	err = o.write(target, cloneOptions, ctx)
+	if err != nil {
+		return target, fmt.Errorf("cannot clone album: %w", err)
+	}
+	return target, nil
+}
+
+func NewAlbumOperations(albumsService albums.AlbumsService, mediaItemsService media_items.MediaItemsService) AlbumOperations {
+	return AlbumOperations{
+		albums:     albumsService,
+		mediaItems: mediaItemsService,
+	}
+}
@@ -0,0 +1,240 @@
+package album_operations
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fake library with source album, existing target album for August and endpoints recording created albums and
+// added items
+type splitServer struct {
+	t       *testing.T
+	m       sync.Mutex
+	created []string
+	added   map[string][]string
+}
+
+func item(id string, creationTime string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            id,
+		"mediaMetadata": map[string]string{"creationTime": creationTime},
+	}
+}
+
+func (s *splitServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/albums/source":
+		_ = encoder.Encode(albums.Album{ID: "source", Title: "Trip"})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/albums":
+		_ = encoder.Encode(map[string]interface{}{
+			"albums": []albums.Album{{ID: "source", Title: "Trip"}, {ID: "existing", Title: "Trip - 2020-08"}},
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/albums":
+		body := struct {
+			Album albums.Album `json:"album"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.created = append(s.created, body.Album.Title)
+		_ = encoder.Encode(albums.Album{ID: "created", Title: body.Album.Title})
+	case r.URL.Path == "/v1/mediaItems:search":
+		body := media_items.SearchOptions{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		items := map[string][]map[string]interface{}{
+			"source": {
+				item("a", "2020-08-01T10:00:00Z"),
+				item("b", "2020-08-02T10:00:00Z"),
+				item("c", "2020-09-01T10:00:00Z"),
+			},
+			"existing": {item("a", "2020-08-01T10:00:00Z")},
+		}
+		_ = encoder.Encode(map[string]interface{}{"mediaItems": items[body.AlbumId]})
+	case r.URL.Path == "/v1/albums/existing:batchAddMediaItems" || r.URL.Path == "/v1/albums/created:batchAddMediaItems":
+		body := struct {
+			MediaItemIds []string `json:"mediaItemIds"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		albumId := r.URL.Path[len("/v1/albums/") : len(r.URL.Path)-len(":batchAddMediaItems")]
+		s.added[albumId] = append(s.added[albumId], body.MediaItemIds...)
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+func TestSplitByMonthReusesExistingAlbums(t *testing.T) {
+	server := &splitServer{t: t, added: make(map[string][]string)}
+	client := test_utils.NewClient(t, server)
+	o := NewAlbumOperations(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil))
+	result, err := o.SplitByMonth("source", &SplitOptions{
+		Options:  Options{Deduplicate: true},
+		Location: time.UTC,
+	}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []TargetAlbum{
+		{AlbumId: "existing", Title: "Trip - 2020-08", MediaItemIds: []string{"b"}, Created: false},
+		{AlbumId: "created", Title: "Trip - 2020-09", MediaItemIds: []string{"c"}, Created: true},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result)
+	}
+	if !reflect.DeepEqual(server.created, []string{"Trip - 2020-09"}) {
+		t.Errorf("expected only missing album to be created, got %v", server.created)
+	}
+	expectedAdded := map[string][]string{"existing": {"b"}, "created": {"c"}}
+	if !reflect.DeepEqual(server.added, expectedAdded) {
+		t.Errorf("expected added items %v, got %v", expectedAdded, server.added)
+	}
+}
+
+func newTestLibrary(t *testing.T) (AlbumOperations, *test_utils.Library) {
+	library := test_utils.NewLibrary(t)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		library.AddMediaItems(test_utils.LibraryMediaItem{ID: id, CreationTime: "2020-08-01T10:00:00Z"})
+	}
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "first", Title: "First", CoverPhotoMediaItemId: "b", MediaItemIds: []string{"a", "b"}},
+		test_utils.LibraryAlbum{ID: "second", Title: "Second", CoverPhotoMediaItemId: "c", MediaItemIds: []string{"b", "c"}},
+		test_utils.LibraryAlbum{ID: "merged", Title: "Merged", MediaItemIds: []string{"c", "d"}},
+	)
+	client := test_utils.NewClient(t, library)
+	return NewAlbumOperations(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil)), library
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name            string
+		title           string
+		options         *Options
+		expected        TargetAlbum
+		expectedInAlbum []string
+	}{
+		{
+			"new album",
+			"Best",
+			nil,
+			TargetAlbum{AlbumId: "created-1", Title: "Best", MediaItemIds: []string{"a", "b", "c"}, Created: true},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"new album with cover",
+			"Best",
+			&Options{CopyCover: true},
+			TargetAlbum{AlbumId: "created-1", Title: "Best", MediaItemIds: []string{"a", "b", "c"}, CoverPhotoMediaItemId: "b", Created: true},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"existing album",
+			"Merged",
+			nil,
+			TargetAlbum{AlbumId: "merged", Title: "Merged", MediaItemIds: []string{"a", "b", "c"}},
+			[]string{"c", "d", "a", "b", "c"},
+		},
+		{
+			"existing album deduplicated",
+			"Merged",
+			&Options{Deduplicate: true},
+			TargetAlbum{AlbumId: "merged", Title: "Merged", MediaItemIds: []string{"a", "b"}},
+			[]string{"c", "d", "a", "b"},
+		},
+		{
+			"dry run",
+			"Best",
+			&Options{DryRun: true, CopyCover: true},
+			TargetAlbum{Title: "Best", MediaItemIds: []string{"a", "b", "c"}, CoverPhotoMediaItemId: "b", Created: true},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, library := newTestLibrary(t)
+			result, err := o.Merge([]string{"first", "second"}, tt.title, tt.options, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*result, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, *result)
+			}
+			if tt.expectedInAlbum == nil {
+				if len(library.AlbumTitles()) != 3 {
+					t.Errorf("dry run shouldn't create albums, got %v", library.AlbumTitles())
+				}
+				return
+			}
+			album := library.Album(tt.expected.AlbumId)
+			if !reflect.DeepEqual(album.MediaItemIds, tt.expectedInAlbum) {
+				t.Errorf("expected album items %v, got %v", tt.expectedInAlbum, album.MediaItemIds)
+			}
+			if album.CoverPhotoMediaItemId != tt.expected.CoverPhotoMediaItemId {
+				t.Errorf("expected cover %q, got %q", tt.expected.CoverPhotoMediaItemId, album.CoverPhotoMediaItemId)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       *Options
+		expectedCover string
+	}{
+		{"without cover", nil, ""},
+		{"with cover", &Options{CopyCover: true}, "c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, library := newTestLibrary(t)
+			result, err := o.Clone("second", "Second copy", tt.options, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			album := library.Album(result.AlbumId)
+			if album == nil || album.Title != "Second copy" || !result.Created {
+				t.Fatalf("expected new album, got %+v", result)
+			}
+			if !reflect.DeepEqual(album.MediaItemIds, []string{"b", "c"}) {
+				t.Errorf("expected items in source order, got %v", album.MediaItemIds)
+			}
+			if album.CoverPhotoMediaItemId != tt.expectedCover || result.CoverPhotoMediaItemId != tt.expectedCover {
+				t.Errorf("expected cover %q, got %q", tt.expectedCover, album.CoverPhotoMediaItemId)
+			}
+		})
+	}
+}
+
+func TestAmbiguousTargetTitle(t *testing.T) {
+	o, library := newTestLibrary(t)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "merged-2", Title: "Merged"},
+		test_utils.LibraryAlbum{ID: "first-2020-08", Title: "First - 2020-08"},
+		test_utils.LibraryAlbum{ID: "first-2020-08-2", Title: "First - 2020-08"},
+	)
+	_, err := o.Merge([]string{"first", "second"}, "Merged", nil, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "multiple albums titled 'Merged'") {
+		t.Errorf("expected ambiguous title error, got %v", err)
+	}
+	_, err = o.SplitByMonth("first", &SplitOptions{Location: time.UTC}, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "multiple albums titled 'First - 2020-08'") {
+		t.Errorf("expected ambiguous title error, got %v", err)
+	}
+	for _, request := range library.Requests() {
+		if !strings.HasPrefix(request, "GET") && request != "POST /v1/mediaItems:search" {
+			t.Errorf("nothing should be written, got %s", request)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package album_story
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"time"
+)
+
+const dayKeyLayout = "2006-01-02"
+
+type DayHeadersOptions struct {
+	// Time zone used to determine calendar day of media item. Defaults to time.Local
+	Location *time.Location
+	// Returns header text for day. dayNumber starts at 1 for the first day in album.
+	// Defaults to "Day 3 — Tuesday, 12 March" format
+	Format func(dayNumber int, day time.Time) string
+}
+
+// Header created for single day
+type DayHeader struct {
+	// Day in YYYY-MM-DD format
+	Day              string
+	Text             string
+	EnrichmentItemId string
+}
+
+// Remembers enrichment items created as day headers. API doesn't allow listing enrichments so it's the only way
+// to avoid duplicated headers
+type DayHeadersStore interface {
+	// Returns map of day (YYYY-MM-DD) to enrichment item ID for album
+	Get(albumId string) (map[string]string, error)
+	Save(albumId string, headers map[string]string) error
+}
+
+// Store keeping headers of all albums in single JSON file
+type FileDayHeadersStore struct {
+	file internal.JSONFileStore
+}
+
+func (s FileDayHeadersStore) Get(albumId string) (map[string]string, error) {
+	albumsHeaders := make(map[string]map[string]string)
+	err := s.file.Load(&albumsHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load day headers: %w", err)
+	}
+	headers := albumsHeaders[albumId]
+	if headers == nil {
+		headers = make(map[string]string)
+	}
+	return headers, nil
+}
+
+func (s FileDayHeadersStore) Save(albumId string, headers map[string]string) error {
+	albumsHeaders := make(map[string]map[string]string)
+	err := s.file.Update(&albumsHeaders, func() {
+		albumsHeaders[albumId] = headers
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save day headers: %w", err)
+	}
+	return nil
+}
+
+func NewFileDayHeadersStore(path string) FileDayHeadersStore {
+	return FileDayHeadersStore{
+		file: internal.NewJSONFileStore(path),
+	}
+}
+
+func defaultDayHeaderFormat(dayNumber int, day time.Time) string {
+	return fmt.Sprintf("Day %d — %s", dayNumber, day.Format("Monday, 2 January"))
+}
+
+type dayGroup struct {
+	day time.Time
+	// index of first item in group
+	start int
+}
+
+// Groups consecutive media items by local calendar day
+func groupByDay(items []media_items.MediaItem, location *time.Location) ([]dayGroup, error) {
+	result := make([]dayGroup, 0)
+	for i, item := range items {
+		creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+		}
+		localTime := creationTime.In(location)
+		day := time.Date(localTime.Year(), localTime.Month(), localTime.Day(), 0, 0, 0, 0, location)
+		if len(result) > 0 && result[len(result)-1].day.Equal(day) {
+			continue
+		}
+		result = append(result, dayGroup{
+			day:   day,
+			start: i,
+		})
+	}
+	return result, nil
+}
+
+// Inserts text enrichment header before each group of media items taken on the same day. Items are taken in album
+// order so album should be ordered by creation time. Headers created in previous runs (remembered by store) are
+// not duplicated. Returns headers created in this run
+func (b StoryBuilder) AddDayHeaders(albumId string, store DayHeadersStore, options *DayHeadersOptions, ctx context.Context) ([]DayHeader, error) {
+	headersOptions := DayHeadersOptions{
+		Location: time.Local,
+		Format:   defaultDayHeaderFormat,
+	}
+	if options != nil {
+		if options.Location != nil {
+			headersOptions.Location = options.Location
+		}
+		if options.Format != nil {
+			headersOptions.Format = options.Format
+		}
+	}
+	items, err := b.mediaItems.SearchAll(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list album items: %w", err)
+	}
+	groups, err := groupByDay(items, headersOptions.Location)
+	if err != nil {
+		return nil, err
+	}
+	existingHeaders, err := store.Get(albumId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get existing day headers: %w", err)
+	}
+	result := make([]DayHeader, 0)
+	for _, group := range groups {
+		dayKey := group.day.Format(dayKeyLayout)
+		if _, ok := existingHeaders[dayKey]; ok {
+			continue
+		}
+		// calendar days are counted in UTC to avoid DST shifting day length
+		firstDay := groups[0].day
+		dayNumber := int(time.Date(group.day.Year(), group.day.Month(), group.day.Day(), 0, 0, 0, 0, time.UTC).
+			Sub(time.Date(firstDay.Year(), firstDay.Month(), firstDay.Day(), 0, 0, 0, 0, time.UTC)).Hours()/24) + 1
+		header := DayHeader{
+			Day:  dayKey,
+			Text: headersOptions.Format(dayNumber, group.day),
+		}
+		position := albums.NewFirstInAlbumPosition()
+		if group.start > 0 {
+			position = albums.NewAfterMediaItemPosition(items[group.start-1].ID)
+		}
+		enrichmentItem, err := b.albums.AddEnrichment(albumId, albums.NewTextEnrichment(header.Text), position, ctx)
+		if err != nil {
+			return result, fmt.Errorf("cannot add header for %s: %w", dayKey, err)
+		}
+		header.EnrichmentItemId = enrichmentItem.Id
+		existingHeaders[dayKey] = enrichmentItem.Id
+		// saved after every header so failure doesn't cause duplicates on next run
+		err = store.Save(albumId, existingHeaders)
+		if err != nil {
+			return result, fmt.Errorf("cannot save day headers: %w", err)
+		}
+		result = append(result, header)
+	}
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package album_story
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Text and position of header sent with addEnrichment request
+type sentHeader struct {
+	text     string
+	position albums.AlbumPosition
+}
+
+func sentHeaders(t *testing.T, album *test_utils.LibraryAlbum) []sentHeader {
+	result := make([]sentHeader, 0)
+	for _, body := range album.Enrichments {
+		request := struct {
+			NewEnrichmentItem albums.NewEnrichmentItem `json:"newEnrichmentItem"`
+			AlbumPosition     albums.AlbumPosition     `json:"albumPosition"`
+		}{}
+		err := json.Unmarshal(body, &request)
+		if err != nil {
+			t.Fatalf("cannot decode enrichment %s: %v", body, err)
+		}
+		if request.NewEnrichmentItem.TextEnrichment == nil {
+			t.Fatalf("expected text enrichment, got %s", body)
+		}
+		result = append(result, sentHeader{request.NewEnrichmentItem.TextEnrichment.Text, request.AlbumPosition})
+	}
+	return result
+}
+
+func newDayHeadersLibrary(t *testing.T, items ...test_utils.LibraryMediaItem) (StoryBuilder, *test_utils.Library) {
+	library := test_utils.NewLibrary(t)
+	library.AddMediaItems(items...)
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	library.AddAlbums(test_utils.LibraryAlbum{ID: "trip", Title: "Trip", MediaItemIds: ids})
+	client := test_utils.NewClient(t, library)
+	return NewStoryBuilder(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil)), library
+}
+
+func TestAddDayHeaders(t *testing.T) {
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	tests := []struct {
+		name     string
+		location *time.Location
+		items    []test_utils.LibraryMediaItem
+		expected []sentHeader
+	}{
+		{"days split in time zone", time.FixedZone("UTC+2", 2*60*60), []test_utils.LibraryMediaItem{
+			{ID: "a", CreationTime: "2024-03-29T21:30:00Z"},
+			// past midnight in UTC+2
+			{ID: "b", CreationTime: "2024-03-29T22:30:00Z"},
+			{ID: "c", CreationTime: "2024-03-30T10:00:00Z"},
+			{ID: "d", CreationTime: "2024-04-01T08:00:00Z"},
+		}, []sentHeader{
+			{"Day 1 — Friday, 29 March", albums.NewFirstInAlbumPosition()},
+			{"Day 2 — Saturday, 30 March", albums.NewAfterMediaItemPosition("a")},
+			// day without items is counted
+			{"Day 4 — Monday, 1 April", albums.NewAfterMediaItemPosition("c")},
+		}},
+		// 30 March and 1 April are only 47 hours apart in Warsaw
+		{"days counted across DST change", warsaw, []test_utils.LibraryMediaItem{
+			{ID: "a", CreationTime: "2024-03-30T12:00:00Z"},
+			{ID: "b", CreationTime: "2024-04-01T11:00:00Z"},
+		}, []sentHeader{
+			{"Day 1 — Saturday, 30 March", albums.NewFirstInAlbumPosition()},
+			{"Day 3 — Monday, 1 April", albums.NewAfterMediaItemPosition("a")},
+		}},
+		{"single day", time.UTC, []test_utils.LibraryMediaItem{
+			{ID: "a", CreationTime: "2024-03-30T08:00:00Z"},
+			{ID: "b", CreationTime: "2024-03-30T20:00:00Z"},
+		}, []sentHeader{
+			{"Day 1 — Saturday, 30 March", albums.NewFirstInAlbumPosition()},
+		}},
+		{"empty album", time.UTC, []test_utils.LibraryMediaItem{}, []sentHeader{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder, library := newDayHeadersLibrary(t, tt.items...)
+			store := NewFileDayHeadersStore(filepath.Join(t.TempDir(), "headers.json"))
+			result, err := builder.AddDayHeaders("trip", store, &DayHeadersOptions{Location: tt.location}, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := sentHeaders(t, library.Album("trip")); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected headers %+v, got %+v", tt.expected, got)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d created headers, got %+v", len(tt.expected), result)
+			}
+			for i, header := range result {
+				if header.Text != tt.expected[i].text || header.EnrichmentItemId == "" {
+					t.Errorf("unexpected header %+v", header)
+				}
+			}
+		})
+	}
+}
+
+func TestAddDayHeadersRerun(t *testing.T) {
+	builder, library := newDayHeadersLibrary(t,
+		test_utils.LibraryMediaItem{ID: "a", CreationTime: "2024-03-29T10:00:00Z"},
+		test_utils.LibraryMediaItem{ID: "b", CreationTime: "2024-03-30T10:00:00Z"},
+	)
+	path := filepath.Join(t.TempDir(), "headers.json")
+	options := &DayHeadersOptions{
+		Location: time.UTC,
+		Format: func(dayNumber int, day time.Time) string {
+			return day.Format(dayKeyLayout)
+		},
+	}
+	created, err := builder.AddDayHeaders("trip", NewFileDayHeadersStore(path), options, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("expected 2 headers, got %+v", created)
+	}
+	// store is re-created to check that headers are read from file
+	rerun, err := builder.AddDayHeaders("trip", NewFileDayHeadersStore(path), options, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rerun) != 0 || len(library.Album("trip").Enrichments) != 2 {
+		t.Errorf("expected no headers to be added on re-run, got %+v", rerun)
+	}
+	library.AddMediaItems(test_utils.LibraryMediaItem{ID: "c", CreationTime: "2024-03-31T10:00:00Z"})
+	err = builder.albums.BatchAddMediaItems("trip", []string{"c"}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, err := builder.AddDayHeaders("trip", NewFileDayHeadersStore(path), options, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(added) != 1 || added[0].Day != "2024-03-31" {
+		t.Errorf("expected only header of new day, got %+v", added)
+	}
+	headers := sentHeaders(t, library.Album("trip"))
+	expected := sentHeader{"2024-03-31", albums.NewAfterMediaItemPosition("b")}
+	if len(headers) != 3 || headers[2] != expected {
+		t.Errorf("expected %+v to be added, got %+v", expected, headers)
+	}
+	saved, err := NewFileDayHeadersStore(path).Get("trip")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(saved) != 3 || saved["2024-03-31"] != added[0].EnrichmentItemId {
+		t.Errorf("expected all headers to be saved, got %v", saved)
+	}
+	other, err := NewFileDayHeadersStore(path).Get("other")
+	if err != nil || len(other) != 0 {
+		t.Errorf("expected no headers of other album, got %v (%v)", other, err)
+	}
+}
@@ -0,0 +1,232 @@
+package album_story
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Portable representation of album contents in album order
+type Document struct {
+	Title   string
+	Entries []DocumentEntry
+}
+
+// Either media item (MediaItemId is set) or enrichment (Enrichment is set)
+type DocumentEntry struct {
+	MediaItemId string
+	Filename    string
+	Enrichment  *albums.NewEnrichmentItem
+}
+
+var (
+	markdownMediaItemRegexp = regexp.MustCompile("^\\d+\\.\\s+`([^`]+)`\\s*(.*)$")
+	markdownLocationRegexp  = regexp.MustCompile(`^(.*) \((-?[0-9.]+), (-?[0-9.]+)\)$`)
+)
+
+const (
+	markdownLocationPrefix = "Location: "
+	markdownMapPrefix      = "Map: "
+	markdownMapSeparator   = " -> "
+)
+
+func formatMarkdownLocation(location albums.Location) string {
+	return fmt.Sprintf("%s (%s, %s)",
+		location.LocationName,
+		strconv.FormatFloat(location.LatLng.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(location.LatLng.Longitude, 'f', -1, 64),
+	)
+}
+
+func parseMarkdownLocation(s string) (albums.Location, error) {
+	matches := markdownLocationRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return albums.Location{}, fmt.Errorf("invalid location '%s'", s)
+	}
+	latitude, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return albums.Location{}, fmt.Errorf("invalid latitude in '%s': %w", s, err)
+	}
+	longitude, err := strconv.ParseFloat(matches[3], 64)
+	if err != nil {
+		return albums.Location{}, fmt.Errorf("invalid longitude in '%s': %w", s, err)
+	}
+	return albums.Location{
+		LocationName: matches[1],
+		LatLng: albums.LatLng{
+			Latitude:  latitude,
+			Longitude: longitude,
+		},
+	}, nil
+}
+
+// Renders document as Markdown. Title is rendered as top level heading, text enrichments as second level
+// headings, location and map enrichments as "Location:"/"Map:" lines and media items as ordered list
+func (d Document) Markdown() string {
+	b := &strings.Builder{}
+	b.WriteString("# " + d.Title + "\n")
+	itemNumber := 0
+	previousWasItem := false
+	for _, entry := range d.Entries {
+		if entry.Enrichment == nil {
+			if !previousWasItem {
+				b.WriteString("\n")
+			}
+			itemNumber++
+			b.WriteString(strings.TrimSpace(fmt.Sprintf("%d. `%s` %s", itemNumber, entry.MediaItemId, entry.Filename)) + "\n")
+			previousWasItem = true
+			continue
+		}
+		b.WriteString("\n")
+		previousWasItem = false
+		enrichment := entry.Enrichment
+		switch {
+		case enrichment.TextEnrichment != nil:
+			// headings are single line
+			b.WriteString("## " + strings.Join(strings.Fields(enrichment.TextEnrichment.Text), " ") + "\n")
+		case enrichment.LocationEnrichment != nil:
+			b.WriteString(markdownLocationPrefix + formatMarkdownLocation(enrichment.LocationEnrichment.Location) + "\n")
+		case enrichment.MapEnrichment != nil:
+			b.WriteString(markdownMapPrefix + formatMarkdownLocation(enrichment.MapEnrichment.Origin) +
+				markdownMapSeparator + formatMarkdownLocation(enrichment.MapEnrichment.Destination) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Parses document rendered by Document.Markdown. Lines that are not recognized are ignored
+func ParseMarkdown(r io.Reader) (*Document, error) {
+	result := &Document{
+		Entries: make([]DocumentEntry, 0),
+	}
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		var enrichment albums.NewEnrichmentItem
+		switch {
+		case strings.HasPrefix(line, "## "):
+			enrichment = albums.NewTextEnrichment(strings.TrimSpace(strings.TrimPrefix(line, "## ")))
+		case strings.HasPrefix(line, "# "):
+			if result.Title == "" {
+				result.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			}
+			continue
+		case strings.HasPrefix(line, markdownLocationPrefix):
+			location, err := parseMarkdownLocation(strings.TrimPrefix(line, markdownLocationPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			enrichment = albums.NewLocationEnrichment(location)
+		case strings.HasPrefix(line, markdownMapPrefix):
+			parts := strings.SplitN(strings.TrimPrefix(line, markdownMapPrefix), markdownMapSeparator, 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("line %d: map requires origin and destination", lineNumber)
+			}
+			origin, err := parseMarkdownLocation(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			destination, err := parseMarkdownLocation(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			enrichment = albums.NewMapEnrichment(origin, destination)
+		default:
+			matches := markdownMediaItemRegexp.FindStringSubmatch(line)
+			if matches != nil {
+				result.Entries = append(result.Entries, DocumentEntry{
+					MediaItemId: matches[1],
+					Filename:    strings.TrimSpace(matches[2]),
+				})
+			}
+			continue
+		}
+		result.Entries = append(result.Entries, DocumentEntry{
+			Enrichment: &enrichment,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read document: %w", err)
+	}
+	return result, nil
+}
+
+// Exports album as document. API doesn't allow listing enrichments so only media items are exported and album
+// imported from exported document has no enrichments unless they are added to document
+func (b StoryBuilder) Export(albumId string, ctx context.Context) (*Document, error) {
+	album, err := b.albums.Get(albumId, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get album: %w", err)
+	}
+	items, err := b.mediaItems.SearchAll(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list album items: %w", err)
+	}
+	result := &Document{
+		Title:   album.Title,
+		Entries: make([]DocumentEntry, 0, len(items)),
+	}
+	for _, item := range items {
+		result.Entries = append(result.Entries, DocumentEntry{
+			MediaItemId: item.ID,
+			Filename:    item.Filename,
+		})
+	}
+	return result, nil
+}
+
+// Creates new album from document. Media items are added in document order with BatchAddMediaItemsAll (BatchCreate
+// positions apply only to newly uploaded items) and enrichments are inserted after their preceding entries.
+// API allows adding only media items uploaded by this app, album is returned with error when any item is rejected
+func (b StoryBuilder) Import(document Document, ctx context.Context) (*albums.Album, error) {
+	album, err := b.albums.Create(document.Title, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create album: %w", err)
+	}
+	mediaItemIds := make([]string, 0, len(document.Entries))
+	insertions := make([]Insertion, 0)
+	previousEntry := -1
+	for i, entry := range document.Entries {
+		if entry.Enrichment == nil {
+			mediaItemIds = append(mediaItemIds, entry.MediaItemId)
+			previousEntry = i
+			continue
+		}
+		insertion := Insertion{
+			Enrichment: *entry.Enrichment,
+			Position:   albums.NewFirstInAlbumPosition(),
+		}
+		switch {
+		case previousEntry == -1:
+		case document.Entries[previousEntry].Enrichment == nil:
+			insertion.Position = albums.NewAfterMediaItemPosition(document.Entries[previousEntry].MediaItemId)
+		default:
+			insertion.AfterPrevious = true
+		}
+		insertions = append(insertions, insertion)
+		previousEntry = i
+	}
+	// single worker keeps document order
+	_, err = b.albums.BatchAddMediaItemsAll(album.ID, mediaItemIds, &albums.BatchMediaItemsOptions{
+		Concurrency: 1,
+	}, ctx)
+	if err != nil {
+		return album, fmt.Errorf("cannot add media items: %w", err)
+	}
+	_, err = b.Apply(album.ID, insertions, ctx)
+	if err != nil {
+		return album, fmt.Errorf("cannot add enrichments: %w", err)
+	}
+	return album, nil
+}
@@ -0,0 +1,77 @@
+package album_story
+
+import (
+	"github.com/duffpl/google-photos-api-client/albums"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	start := albums.Location{LocationName: "Kraków", LatLng: albums.LatLng{Latitude: 50.0614, Longitude: 19.9366}}
+	end := albums.Location{LocationName: "Zakopane", LatLng: albums.LatLng{Latitude: 49.2992, Longitude: -19.9496}}
+	text := albums.NewTextEnrichment("Day 1")
+	location := albums.NewLocationEnrichment(start)
+	mapEnrichment := albums.NewMapEnrichment(start, end)
+	tests := []struct {
+		name     string
+		document Document
+	}{
+		{"empty", Document{Title: "Empty", Entries: []DocumentEntry{}}},
+		{"media items", Document{Title: "Items", Entries: []DocumentEntry{
+			{MediaItemId: "a", Filename: "a.jpg"},
+			{MediaItemId: "b"},
+		}}},
+		{"enrichments", Document{Title: "Trip", Entries: []DocumentEntry{
+			{Enrichment: &text},
+			{MediaItemId: "a", Filename: "a.jpg"},
+			{Enrichment: &location},
+			{Enrichment: &mapEnrichment},
+			{MediaItemId: "b", Filename: "b.jpg"},
+			{MediaItemId: "c", Filename: "c.jpg"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseMarkdown(strings.NewReader(tt.document.Markdown()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*parsed, tt.document) {
+				t.Errorf("expected %+v, got %+v\nmarkdown:\n%s", tt.document, *parsed, tt.document.Markdown())
+			}
+		})
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	text := albums.NewTextEnrichment("Day 1\nmorning")
+	document := Document{Title: "Trip", Entries: []DocumentEntry{
+		{Enrichment: &text},
+		{MediaItemId: "a", Filename: "a.jpg"},
+		{MediaItemId: "b", Filename: "b.jpg"},
+	}}
+	expected := "# Trip\n\n## Day 1 morning\n\n1. `a` a.jpg\n2. `b` b.jpg\n"
+	if markdown := document.Markdown(); markdown != expected {
+		t.Errorf("expected %q, got %q", expected, markdown)
+	}
+}
+
+func TestParseMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		markdown      string
+		expectedError string
+	}{
+		{"invalid location", "# Trip\nLocation: Kraków\n", "line 2: invalid location"},
+		{"map without destination", "# Trip\nMap: Kraków (50, 19)\n", "line 2: map requires origin and destination"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMarkdown(strings.NewReader(tt.markdown))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package album_story
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"github.com/imdario/mergo"
+	"sort"
+	"time"
+)
+
+type StoryOptions struct {
+	// Map enrichment is inserted between consecutive media items at least that many meters apart. Defaults to 1000
+	MinMapDistance float64
+	// Media items taken further than that from the nearest track point are not located. Defaults to 1 hour
+	MaxTimeGap time.Duration
+	// Waypoint within that many meters from media item location is used as location name. Defaults to 500
+	MaxWaypointDistance float64
+}
+
+// Enrichment with its position in album
+type Insertion struct {
+	Enrichment albums.NewEnrichmentItem
+	// Ignored when AfterPrevious is set
+	Position albums.AlbumPosition
+	// Insert directly after enrichment created by previous insertion. Used when multiple enrichments are placed
+	// after the same media item
+	AfterPrevious bool
+}
+
+type locatedItem struct {
+	index  int
+	latLng albums.LatLng
+	name   string
+}
+
+// Builds album story from GPS track and media items. Items are ordered by creation time and each item is located
+// on track. Location enrichment is placed before the first located item, map enrichments are placed between
+// items that are far enough from each other (followed by location enrichment when item is close to a named
+// waypoint). Album is expected to be ordered by creation time
+func PlanStory(track Track, items []media_items.MediaItem, options *StoryOptions) ([]Insertion, error) {
+	storyOptions := StoryOptions{
+		MinMapDistance:      1000,
+		MaxTimeGap:          time.Hour,
+		MaxWaypointDistance: 500,
+	}
+	if options != nil {
+		_ = mergo.Merge(&storyOptions, options, mergo.WithOverride)
+	}
+	if len(track.Points) == 0 {
+		return nil, errors.New("track doesn't contain any timed points")
+	}
+	sortedItems, err := sortByCreationTime(items)
+	if err != nil {
+		return nil, err
+	}
+	positionBefore := func(index int) albums.AlbumPosition {
+		if index == 0 {
+			return albums.NewFirstInAlbumPosition()
+		}
+		return albums.NewAfterMediaItemPosition(sortedItems[index-1].item.ID)
+	}
+	location := func(located locatedItem) albums.Location {
+		name := located.name
+		if name == "" {
+			name = fmt.Sprintf("%.5f, %.5f", located.latLng.Latitude, located.latLng.Longitude)
+		}
+		return albums.Location{
+			LocationName: name,
+			LatLng:       located.latLng,
+		}
+	}
+	result := make([]Insertion, 0)
+	var previous *locatedItem
+	for i, item := range sortedItems {
+		latLng, ok := track.LocationAt(item.creationTime, storyOptions.MaxTimeGap)
+		if !ok {
+			continue
+		}
+		current := &locatedItem{
+			index:  i,
+			latLng: latLng,
+			name:   track.NameAt(latLng, storyOptions.MaxWaypointDistance),
+		}
+		if previous == nil {
+			result = append(result, Insertion{
+				Enrichment: albums.NewLocationEnrichment(location(*current)),
+				Position:   positionBefore(i),
+			})
+			previous = current
+			continue
+		}
+		if Distance(previous.latLng, current.latLng) < storyOptions.MinMapDistance {
+			continue
+		}
+		result = append(result, Insertion{
+			Enrichment: albums.NewMapEnrichment(location(*previous), location(*current)),
+			Position:   positionBefore(i),
+		})
+		if current.name != "" && current.name != previous.name {
+			result = append(result, Insertion{
+				Enrichment:    albums.NewLocationEnrichment(location(*current)),
+				AfterPrevious: true,
+			})
+		}
+		previous = current
+	}
+	return result, nil
+}
+
+type timedItem struct {
+	item         media_items.MediaItem
+	creationTime time.Time
+}
+
+func sortByCreationTime(items []media_items.MediaItem) ([]timedItem, error) {
+	result := make([]timedItem, 0, len(items))
+	for _, item := range items {
+		creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+		}
+		result = append(result, timedItem{
+			item:         item,
+			creationTime: creationTime,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].creationTime.Before(result[j].creationTime)
+	})
+	return result, nil
+}
+
+// Builds and applies album stories using albums and media items services
+type StoryBuilder struct {
+	albums     albums.AlbumsService
+	mediaItems media_items.MediaItemsService
+}
+
+// Fetches items of album specified by id and plans story for them. See PlanStory
+func (b StoryBuilder) Plan(albumId string, track Track, options *StoryOptions, ctx context.Context) ([]Insertion, error) {
+	items, err := b.mediaItems.SearchAll(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list album items: %w", err)
+	}
+	return PlanStory(track, items, options)
+}
+
+// Adds planned enrichments to album. Returns created enrichment items in the same order as insertions. On error
+// items created so far are returned
+func (b StoryBuilder) Apply(albumId string, insertions []Insertion, ctx context.Context) ([]albums.EnrichmentItem, error) {
+	result := make([]albums.EnrichmentItem, 0, len(insertions))
+	for i, insertion := range insertions {
+		position := insertion.Position
+		if insertion.AfterPrevious {
+			if i == 0 {
+				return result, errors.New("first insertion cannot be placed after previous one")
+			}
+			position = albums.NewAfterEnrichmentItemPosition(result[i-1].Id)
+		}
+		enrichmentItem, err := b.albums.AddEnrichment(albumId, insertion.Enrichment, position, ctx)
+		if err != nil {
+			return result, fmt.Errorf("cannot add enrichment #%d: %w", i, err)
+		}
+		result = append(result, *enrichmentItem)
+	}
+	return result, nil
+}
+
+func NewStoryBuilder(albumsService albums.AlbumsService, mediaItemsService media_items.MediaItemsService) StoryBuilder {
+	return StoryBuilder{
+		albums:     albumsService,
+		mediaItems: mediaItemsService,
+	}
+}
@@ -0,0 +1,304 @@
+package album_story
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TrackPoint struct {
+	Time   time.Time
+	LatLng albums.LatLng
+	// Name of named points (GPX waypoints, KML placemarks). Empty for regular track points
+	Name string
+}
+
+// GPS track with optional named points used as location names
+type Track struct {
+	Name string
+	// Timed track points ordered by time
+	Points []TrackPoint
+	// Named points (time is optional)
+	Waypoints []TrackPoint
+}
+
+type gpxPoint struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+	Name      string  `xml:"name"`
+	Time      string  `xml:"time"`
+}
+
+type gpxDocument struct {
+	Waypoints []gpxPoint `xml:"wpt"`
+	Tracks    []struct {
+		Name     string `xml:"name"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+// Parses GPX document. Track points from all tracks and segments are merged. Named waypoints and route points
+// are used for location names
+func ParseGPX(r io.Reader) (*Track, error) {
+	document := &gpxDocument{}
+	err := xml.NewDecoder(r).Decode(document)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode GPX: %w", err)
+	}
+	track := &Track{}
+	for _, gpxTrack := range document.Tracks {
+		if track.Name == "" {
+			track.Name = gpxTrack.Name
+		}
+		for _, segment := range gpxTrack.Segments {
+			for _, point := range segment.Points {
+				trackPoint, err := point.toTrackPoint()
+				if err != nil {
+					return nil, err
+				}
+				if !trackPoint.Time.IsZero() {
+					track.Points = append(track.Points, trackPoint)
+				}
+			}
+		}
+	}
+	namedPoints := document.Waypoints
+	for _, route := range document.Routes {
+		namedPoints = append(namedPoints, route.Points...)
+	}
+	for _, point := range namedPoints {
+		if point.Name == "" {
+			continue
+		}
+		trackPoint, err := point.toTrackPoint()
+		if err != nil {
+			return nil, err
+		}
+		track.Waypoints = append(track.Waypoints, trackPoint)
+	}
+	return track.normalize()
+}
+
+func (p gpxPoint) toTrackPoint() (TrackPoint, error) {
+	result := TrackPoint{
+		LatLng: albums.LatLng{
+			Latitude:  p.Latitude,
+			Longitude: p.Longitude,
+		},
+		Name: strings.TrimSpace(p.Name),
+	}
+	if p.Time != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(p.Time))
+		if err != nil {
+			return result, fmt.Errorf("invalid point time '%s': %w", p.Time, err)
+		}
+		result.Time = t
+	}
+	return result, nil
+}
+
+type kmlPlacemark struct {
+	Name      string `xml:"name"`
+	TimeStamp struct {
+		When string `xml:"when"`
+	} `xml:"TimeStamp"`
+	Point struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point"`
+	// gx:Track elements. Namespace is not specified so they are matched by local name
+	Tracks []kmlTrack `xml:"Track"`
+	// gx:MultiTrack elements
+	MultiTracks []struct {
+		Tracks []kmlTrack `xml:"Track"`
+	} `xml:"MultiTrack"`
+}
+
+type kmlTrack struct {
+	When   []string `xml:"when"`
+	Coords []string `xml:"coord"`
+}
+
+// Parses KML document. Timed points are read from gx:Track elements and placemarks with TimeStamp. Named
+// placemarks with Point are used for location names
+func ParseKML(r io.Reader) (*Track, error) {
+	decoder := xml.NewDecoder(r)
+	track := &Track{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode KML: %w", err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if element.Name.Local == "name" && track.Name == "" {
+			// first name in document is name of document/folder
+			_ = decoder.DecodeElement(&track.Name, &element)
+			track.Name = strings.TrimSpace(track.Name)
+			continue
+		}
+		if element.Name.Local != "Placemark" {
+			continue
+		}
+		placemark := &kmlPlacemark{}
+		err = decoder.DecodeElement(placemark, &element)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode KML placemark: %w", err)
+		}
+		err = placemark.appendTo(track)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return track.normalize()
+}
+
+func (p kmlPlacemark) appendTo(track *Track) error {
+	kmlTracks := p.Tracks
+	for _, multiTrack := range p.MultiTracks {
+		kmlTracks = append(kmlTracks, multiTrack.Tracks...)
+	}
+	for _, kmlTrack := range kmlTracks {
+		if len(kmlTrack.When) != len(kmlTrack.Coords) {
+			return errors.New("number of when and coord elements in track differs")
+		}
+		for i := range kmlTrack.When {
+			latLng, err := parseKMLCoordinates(kmlTrack.Coords[i], " ")
+			if err != nil {
+				return err
+			}
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(kmlTrack.When[i]))
+			if err != nil {
+				return fmt.Errorf("invalid track time '%s': %w", kmlTrack.When[i], err)
+			}
+			track.Points = append(track.Points, TrackPoint{
+				Time:   t,
+				LatLng: latLng,
+			})
+		}
+	}
+	if strings.TrimSpace(p.Point.Coordinates) == "" {
+		return nil
+	}
+	latLng, err := parseKMLCoordinates(p.Point.Coordinates, ",")
+	if err != nil {
+		return err
+	}
+	point := TrackPoint{
+		LatLng: latLng,
+		Name:   strings.TrimSpace(p.Name),
+	}
+	if p.TimeStamp.When != "" {
+		point.Time, err = time.Parse(time.RFC3339, strings.TrimSpace(p.TimeStamp.When))
+		if err != nil {
+			return fmt.Errorf("invalid placemark time '%s': %w", p.TimeStamp.When, err)
+		}
+		track.Points = append(track.Points, point)
+	}
+	if point.Name != "" {
+		track.Waypoints = append(track.Waypoints, point)
+	}
+	return nil
+}
+
+// KML coordinates are in "longitude,latitude[,altitude]" order (gx:coord uses spaces as separator)
+func parseKMLCoordinates(coordinates string, separator string) (albums.LatLng, error) {
+	parts := strings.Split(strings.TrimSpace(coordinates), separator)
+	if len(parts) < 2 {
+		return albums.LatLng{}, fmt.Errorf("invalid coordinates '%s'", coordinates)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return albums.LatLng{}, fmt.Errorf("invalid longitude in '%s': %w", coordinates, err)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return albums.LatLng{}, fmt.Errorf("invalid latitude in '%s': %w", coordinates, err)
+	}
+	return albums.LatLng{
+		Latitude:  latitude,
+		Longitude: longitude,
+	}, nil
+}
+
+func (t *Track) normalize() (*Track, error) {
+	if len(t.Points) == 0 {
+		return nil, errors.New("track doesn't contain any timed points")
+	}
+	sort.SliceStable(t.Points, func(i, j int) bool {
+		return t.Points[i].Time.Before(t.Points[j].Time)
+	})
+	return t, nil
+}
+
+// Returns position on track at specified time using linear interpolation between neighbouring points. Returns
+// false when time is further than maxGap from track start/end or from both neighbouring points
+func (t Track) LocationAt(at time.Time, maxGap time.Duration) (albums.LatLng, bool) {
+	points := t.Points
+	i := sort.Search(len(points), func(i int) bool {
+		return !points[i].Time.Before(at)
+	})
+	if i == 0 {
+		return points[0].LatLng, points[0].Time.Sub(at) <= maxGap
+	}
+	if i == len(points) {
+		last := points[len(points)-1]
+		return last.LatLng, at.Sub(last.Time) <= maxGap
+	}
+	before, after := points[i-1], points[i]
+	if at.Sub(before.Time) > maxGap && after.Time.Sub(at) > maxGap {
+		return albums.LatLng{}, false
+	}
+	span := after.Time.Sub(before.Time)
+	if span == 0 {
+		return after.LatLng, true
+	}
+	ratio := float64(at.Sub(before.Time)) / float64(span)
+	return albums.LatLng{
+		Latitude:  before.LatLng.Latitude + (after.LatLng.Latitude-before.LatLng.Latitude)*ratio,
+		Longitude: before.LatLng.Longitude + (after.LatLng.Longitude-before.LatLng.Longitude)*ratio,
+	}, true
+}
+
+// Returns name of nearest waypoint within maxDistance meters
+func (t Track) NameAt(latLng albums.LatLng, maxDistance float64) string {
+	name := ""
+	nearest := maxDistance
+	for _, waypoint := range t.Waypoints {
+		distance := Distance(latLng, waypoint.LatLng)
+		if distance <= nearest {
+			nearest = distance
+			name = waypoint.Name
+		}
+	}
+	return name
+}
+
+// Great-circle distance in meters
+func Distance(a albums.LatLng, b albums.LatLng) float64 {
+	const earthRadius = 6371000
+	toRadians := func(degrees float64) float64 {
+		return degrees * math.Pi / 180
+	}
+	deltaLatitude := toRadians(b.Latitude - a.Latitude)
+	deltaLongitude := toRadians(b.Longitude - a.Longitude)
+	h := math.Sin(deltaLatitude/2)*math.Sin(deltaLatitude/2) +
+		math.Cos(toRadians(a.Latitude))*math.Cos(toRadians(b.Latitude))*math.Sin(deltaLongitude/2)*math.Sin(deltaLongitude/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
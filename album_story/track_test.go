@@ -0,0 +1,175 @@
+package album_story
+
+import (
+	"github.com/duffpl/google-photos-api-client/albums"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseTime(t *testing.T, value string) time.Time {
+	result, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("cannot parse time: %v", err)
+	}
+	return result
+}
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="50.0614" lon="19.9366"><name>Kraków</name></wpt>
+  <wpt lat="50.1" lon="19.9"></wpt>
+  <trk>
+    <name>Trip</name>
+    <trkseg>
+      <trkpt lat="50.0614" lon="19.9366"><time>2020-08-01T10:00:00Z</time></trkpt>
+      <trkpt lat="49.2992" lon="19.9496"><time>2020-08-01T12:00:00Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="49.5" lon="19.9"><time>2020-08-01T11:00:00Z</time></trkpt>
+      <trkpt lat="49.6" lon="19.9"></trkpt>
+    </trkseg>
+  </trk>
+  <rte>
+    <rtept lat="49.2992" lon="19.9496"><name>Zakopane</name></rtept>
+  </rte>
+</gpx>`
+
+func TestParseGPX(t *testing.T) {
+	track, err := ParseGPX(strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if track.Name != "Trip" {
+		t.Errorf("unexpected name %q", track.Name)
+	}
+	expectedPoints := []TrackPoint{
+		{Time: parseTime(t, "2020-08-01T10:00:00Z"), LatLng: albums.LatLng{Latitude: 50.0614, Longitude: 19.9366}},
+		{Time: parseTime(t, "2020-08-01T11:00:00Z"), LatLng: albums.LatLng{Latitude: 49.5, Longitude: 19.9}},
+		{Time: parseTime(t, "2020-08-01T12:00:00Z"), LatLng: albums.LatLng{Latitude: 49.2992, Longitude: 19.9496}},
+	}
+	if !reflect.DeepEqual(track.Points, expectedPoints) {
+		t.Errorf("expected points sorted by time %+v, got %+v", expectedPoints, track.Points)
+	}
+	expectedWaypoints := []TrackPoint{
+		{LatLng: albums.LatLng{Latitude: 50.0614, Longitude: 19.9366}, Name: "Kraków"},
+		{LatLng: albums.LatLng{Latitude: 49.2992, Longitude: 19.9496}, Name: "Zakopane"},
+	}
+	if !reflect.DeepEqual(track.Waypoints, expectedWaypoints) {
+		t.Errorf("expected named waypoints %+v, got %+v", expectedWaypoints, track.Waypoints)
+	}
+}
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+  <Document>
+    <name>Trip</name>
+    <Placemark>
+      <name>Route</name>
+      <gx:Track>
+        <when>2020-08-01T10:00:00Z</when>
+        <when>2020-08-01T12:00:00Z</when>
+        <gx:coord>19.9366 50.0614 220</gx:coord>
+        <gx:coord>19.9496 49.2992 840</gx:coord>
+      </gx:Track>
+    </Placemark>
+    <Placemark>
+      <gx:MultiTrack>
+        <gx:Track>
+          <when>2020-08-01T11:00:00Z</when>
+          <gx:coord>19.9 49.5 0</gx:coord>
+        </gx:Track>
+      </gx:MultiTrack>
+    </Placemark>
+    <Placemark>
+      <name>Kraków</name>
+      <Point><coordinates>19.9366,50.0614,0</coordinates></Point>
+    </Placemark>
+    <Placemark>
+      <name>Zakopane</name>
+      <TimeStamp><when>2020-08-01T13:00:00Z</when></TimeStamp>
+      <Point><coordinates>19.9496,49.2992</coordinates></Point>
+    </Placemark>
+  </Document>
+</kml>`
+
+func TestParseKML(t *testing.T) {
+	track, err := ParseKML(strings.NewReader(testKML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if track.Name != "Trip" {
+		t.Errorf("unexpected name %q", track.Name)
+	}
+	zakopane := TrackPoint{
+		Time:   parseTime(t, "2020-08-01T13:00:00Z"),
+		LatLng: albums.LatLng{Latitude: 49.2992, Longitude: 19.9496},
+		Name:   "Zakopane",
+	}
+	expectedPoints := []TrackPoint{
+		{Time: parseTime(t, "2020-08-01T10:00:00Z"), LatLng: albums.LatLng{Latitude: 50.0614, Longitude: 19.9366}},
+		{Time: parseTime(t, "2020-08-01T11:00:00Z"), LatLng: albums.LatLng{Latitude: 49.5, Longitude: 19.9}},
+		{Time: parseTime(t, "2020-08-01T12:00:00Z"), LatLng: albums.LatLng{Latitude: 49.2992, Longitude: 19.9496}},
+		zakopane,
+	}
+	if !reflect.DeepEqual(track.Points, expectedPoints) {
+		t.Errorf("expected points sorted by time %+v, got %+v", expectedPoints, track.Points)
+	}
+	expectedWaypoints := []TrackPoint{
+		{LatLng: albums.LatLng{Latitude: 50.0614, Longitude: 19.9366}, Name: "Kraków"},
+		zakopane,
+	}
+	if !reflect.DeepEqual(track.Waypoints, expectedWaypoints) {
+		t.Errorf("expected named placemarks %+v, got %+v", expectedWaypoints, track.Waypoints)
+	}
+}
+
+func TestParseTrackErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		parse         func(r io.Reader) (*Track, error)
+		document      string
+		expectedError string
+	}{
+		{"GPX without timed points", ParseGPX, `<gpx><wpt lat="1" lon="2"><name>A</name></wpt></gpx>`, "doesn't contain any timed points"},
+		{"GPX with invalid time", ParseGPX, `<gpx><trk><trkseg><trkpt lat="1" lon="2"><time>yesterday</time></trkpt></trkseg></trk></gpx>`, "invalid point time"},
+		{"invalid XML", ParseGPX, `<gpx>`, "cannot decode GPX"},
+		{"KML track with missing coord", ParseKML, `<kml><Placemark><Track><when>2020-08-01T10:00:00Z</when></Track></Placemark></kml>`, "number of when and coord elements"},
+		{"KML with invalid coordinates", ParseKML, `<kml><Placemark><name>A</name><Point><coordinates>19.9</coordinates></Point></Placemark></kml>`, "invalid coordinates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(strings.NewReader(tt.document))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLocationAt(t *testing.T) {
+	track := Track{Points: []TrackPoint{
+		{Time: parseTime(t, "2020-08-01T10:00:00Z"), LatLng: albums.LatLng{Latitude: 50, Longitude: 20}},
+		{Time: parseTime(t, "2020-08-01T12:00:00Z"), LatLng: albums.LatLng{Latitude: 49, Longitude: 19}},
+	}}
+	tests := []struct {
+		name     string
+		at       string
+		expected albums.LatLng
+		ok       bool
+	}{
+		{"interpolated", "2020-08-01T11:00:00Z", albums.LatLng{Latitude: 49.5, Longitude: 19.5}, true},
+		{"before start within gap", "2020-08-01T09:30:00Z", albums.LatLng{Latitude: 50, Longitude: 20}, true},
+		{"after end outside gap", "2020-08-01T14:00:00Z", albums.LatLng{Latitude: 49, Longitude: 19}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latLng, ok := track.LocationAt(parseTime(t, tt.at), time.Hour)
+			if ok != tt.ok || latLng != tt.expected {
+				t.Errorf("expected %v (%t), got %v (%t)", tt.expected, tt.ok, latLng, ok)
+			}
+		})
+	}
+}
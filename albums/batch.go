@@ -0,0 +1,248 @@
+package albums
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"github.com/imdario/mergo"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	batchOperationAdd    = "add"
+	batchOperationRemove = "remove"
+)
+
+// Serialized into resume token. Applied items are stored as bitset over deduplicated list of requested ids
+type batchResumeState struct {
+	AlbumId     string `json:"a"`
+	Operation   string `json:"o"`
+	Fingerprint string `json:"f"`
+	Applied     []byte `json:"b"`
+}
+
+func batchFingerprint(ids []string) string {
+	sum := sha1.Sum([]byte(strings.Join(ids, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func decodeResumeToken(token string) (*batchResumeState, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode token: %w", err)
+	}
+	state := &batchResumeState{}
+	err = json.Unmarshal(b, state)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal token: %w", err)
+	}
+	return state, nil
+}
+
+func (s batchResumeState) encode() string {
+	b, _ := json.Marshal(s)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s batchResumeState) isApplied(index int) bool {
+	return index/8 < len(s.Applied) && s.Applied[index/8]&(1<<uint(index%8)) != 0
+}
+
+func (s *batchResumeState) setApplied(index int) {
+	s.Applied[index/8] |= 1 << uint(index%8)
+}
+
+func isInvalidArgumentError(err error) bool {
+	apiErr := internal.ApiError{}
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Status == "INVALID_ARGUMENT" || apiErr.Code == 400
+}
+
+// Network errors, throttling and server errors
+func isTransientError(err error) bool {
+	requestErr := internal.RequestError{}
+	if !errors.As(err, &requestErr) {
+		return false
+	}
+	statusCode := requestErr.Meta.StatusCode
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+type batchRun struct {
+	options  BatchMediaItemsOptions
+	batchFn  func(ids []string, ctx context.Context) error
+	indexes  map[string]int
+	total    int
+	m        sync.Mutex
+	state    batchResumeState
+	result   *BatchMediaItemsResult
+	progress int
+}
+
+// Sends chunk with retries. Chunk rejected with invalid argument error is split in halves until the offending
+// item is found
+func (r *batchRun) apply(ids []string, ctx context.Context) {
+	err := r.send(ids, ctx)
+	if err == nil {
+		r.markApplied(ids)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if len(ids) > 1 && isInvalidArgumentError(err) {
+		half := len(ids) / 2
+		r.apply(ids[:half], ctx)
+		r.apply(ids[half:], ctx)
+		return
+	}
+	r.markFailed(ids, err)
+}
+
+// Retries chunk that failed with transient error. Retries of HTTP client (call options) are made within each attempt
+func (r *batchRun) send(ids []string, ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		err := r.batchFn(ids, ctx)
+		if err == nil || attempt >= r.options.Retries || !isTransientError(err) || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(r.options.RetryDelay << uint(attempt)):
+		}
+	}
+}
+
+func (r *batchRun) markApplied(ids []string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, id := range ids {
+		r.state.setApplied(r.indexes[id])
+		r.result.Applied = append(r.result.Applied, id)
+	}
+	r.reportProgress(len(ids))
+}
+
+func (r *batchRun) markFailed(ids []string, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, id := range ids {
+		r.result.Failed = append(r.result.Failed, BatchMediaItemsFailure{
+			MediaItemId: id,
+			Err:         err,
+		})
+	}
+	r.reportProgress(len(ids))
+}
+
+func (r *batchRun) reportProgress(processed int) {
+	r.progress += processed
+	if r.options.Progress != nil {
+		r.options.Progress(r.progress, r.total)
+	}
+}
+
+// Common implementation of BatchAddMediaItemsAll and BatchRemoveMediaItemsAll
+func batchMediaItemsAll(albumId string, mediaItemIds []string, operation string, options *BatchMediaItemsOptions, batchFn func(ids []string, ctx context.Context) error, ctx context.Context) (*BatchMediaItemsResult, error) {
+	requestOptions := BatchMediaItemsOptions{
+		Concurrency: 1,
+		RetryDelay:  time.Second,
+	}
+	if options != nil {
+		_ = mergo.Merge(&requestOptions, options, mergo.WithOverride)
+	}
+	if requestOptions.Concurrency < 1 {
+		requestOptions.Concurrency = 1
+	}
+	ids := internal.UniqueStrings(mediaItemIds)
+	state := batchResumeState{
+		AlbumId:     albumId,
+		Operation:   operation,
+		Fingerprint: batchFingerprint(ids),
+		Applied:     make([]byte, (len(ids)+7)/8),
+	}
+	if requestOptions.ResumeToken != "" {
+		previousState, err := decodeResumeToken(requestOptions.ResumeToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resume token: %w", err)
+		}
+		if previousState.AlbumId != state.AlbumId || previousState.Operation != state.Operation || previousState.Fingerprint != state.Fingerprint {
+			return nil, errors.New("resume token doesn't match album, operation or media item ids")
+		}
+		copy(state.Applied, previousState.Applied)
+	}
+	result := &BatchMediaItemsResult{
+		Applied: make([]string, 0),
+		Failed:  make([]BatchMediaItemsFailure, 0),
+		Skipped: make([]string, 0),
+		Pending: make([]string, 0),
+	}
+	indexes := make(map[string]int, len(ids))
+	pending := make([]string, 0, len(ids))
+	for i, id := range ids {
+		indexes[id] = i
+		if state.isApplied(i) {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		pending = append(pending, id)
+	}
+	run := &batchRun{
+		options: requestOptions,
+		batchFn: batchFn,
+		indexes: indexes,
+		total:   len(pending),
+		state:   state,
+		result:  result,
+	}
+	chunksC := make(chan []string)
+	go func() {
+		defer close(chunksC)
+		for _, chunk := range internal.ChunkStrings(pending, 50) {
+			select {
+			case <-ctx.Done():
+				return
+			case chunksC <- chunk:
+			}
+		}
+	}()
+	wg := sync.WaitGroup{}
+	for w := 0; w < requestOptions.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunksC {
+				run.apply(chunk, ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	result.ResumeToken = run.state.encode()
+	failed := make(map[string]struct{}, len(result.Failed))
+	for _, failure := range result.Failed {
+		failed[failure.MediaItemId] = struct{}{}
+	}
+	for _, id := range pending {
+		if _, ok := failed[id]; !ok && !run.state.isApplied(indexes[id]) {
+			result.Pending = append(result.Pending, id)
+		}
+	}
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("interrupted after %d of %d media items: %w", run.progress, run.total, ctx.Err())
+	}
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("%d of %d media items failed: %w", len(result.Failed), run.total, result.Failed[0].Err)
+	}
+	return result, nil
+}
@@ -0,0 +1,247 @@
+package albums
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Fake album endpoint rejecting chunks that contain invalid ids with INVALID_ARGUMENT error
+type batchServer struct {
+	t          *testing.T
+	invalidIds map[string]bool
+	// number of requests answered with 503 before server starts accepting them
+	unavailable int32
+	// called before response is written
+	onRequest func(ids []string)
+	m         sync.Mutex
+	chunks    [][]string
+}
+
+func (s *batchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/albums/album:batchAddMediaItems" {
+		s.t.Errorf("unexpected path %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	body := mediaItemsRequestBody{}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		s.t.Errorf("cannot decode body: %v", err)
+	}
+	if len(body.MediaItemIds) == 0 || len(body.MediaItemIds) > 50 {
+		s.t.Errorf("unexpected chunk size %d", len(body.MediaItemIds))
+	}
+	s.m.Lock()
+	s.chunks = append(s.chunks, body.MediaItemIds)
+	s.m.Unlock()
+	if s.onRequest != nil {
+		s.onRequest(body.MediaItemIds)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if atomic.AddInt32(&s.unavailable, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"code":503,"message":"The service is currently unavailable.","status":"UNAVAILABLE"}}`))
+		return
+	}
+	for _, id := range body.MediaItemIds {
+		if s.invalidIds[id] {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Request contains an invalid media item id.","status":"INVALID_ARGUMENT"}}`))
+			return
+		}
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+func (s *batchServer) requests() [][]string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([][]string{}, s.chunks...)
+}
+
+func testIds(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = "item-" + strconv.Itoa(i)
+	}
+	return ids
+}
+
+func TestBatchAddMediaItemsAll(t *testing.T) {
+	tests := []struct {
+		name             string
+		ids              int
+		concurrency      int
+		invalidIds       []string
+		expectedRequests int
+	}{
+		{"no ids", 0, 1, nil, 0},
+		{"single chunk", 50, 1, nil, 1},
+		{"chunk boundary", 51, 1, nil, 2},
+		{"many chunks", 500, 1, nil, 10},
+		{"many chunks in parallel", 500, 4, nil, 10},
+		// bisection of 50 items chunk sends both halves on 6 levels (50 -> 25 -> 12/13 -> ... -> 1)
+		{"invalid id", 50, 1, []string{"item-7"}, 13},
+		{"invalid ids in parallel", 500, 4, []string{"item-420", "item-7"}, 34},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &batchServer{t: t, invalidIds: make(map[string]bool)}
+			for _, id := range tt.invalidIds {
+				server.invalidIds[id] = true
+			}
+			s := NewHttpAlbumsService(test_utils.NewClient(t, server))
+			ids := testIds(tt.ids)
+			result, err := s.BatchAddMediaItemsAll("album", ids, &BatchMediaItemsOptions{Concurrency: tt.concurrency}, context.Background())
+			if len(tt.invalidIds) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tt.invalidIds) > 0 && err == nil {
+				t.Fatal("expected error")
+			}
+			if got := len(server.requests()); got != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, got)
+			}
+			failed := make([]string, 0)
+			for _, failure := range result.Failed {
+				failed = append(failed, failure.MediaItemId)
+				if !isInvalidArgumentError(failure.Err) {
+					t.Errorf("%s: unexpected error %v", failure.MediaItemId, failure.Err)
+				}
+			}
+			sort.Strings(failed)
+			if strings.Join(failed, ",") != strings.Join(tt.invalidIds, ",") {
+				t.Errorf("expected %v to fail, got %v", tt.invalidIds, failed)
+			}
+			if len(result.Applied)+len(result.Failed) != len(ids) {
+				t.Errorf("expected %d processed items, got %d applied and %d failed", len(ids), len(result.Applied), len(result.Failed))
+			}
+			if tt.concurrency == 1 && len(tt.invalidIds) == 0 {
+				sent := make([]string, 0)
+				for _, chunk := range server.requests() {
+					sent = append(sent, chunk...)
+				}
+				if strings.Join(sent, ",") != strings.Join(ids, ",") || strings.Join(result.Applied, ",") != strings.Join(ids, ",") {
+					t.Error("items were not added in requested order")
+				}
+			}
+		})
+	}
+}
+
+func TestBatchAddMediaItemsAllResume(t *testing.T) {
+	server := &batchServer{t: t, invalidIds: map[string]bool{"item-60": true}}
+	s := NewHttpAlbumsService(test_utils.NewClient(t, server))
+	ids := testIds(100)
+	result, err := s.BatchAddMediaItemsAll("album", ids, nil, context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(result.Applied) != 99 {
+		t.Fatalf("expected 99 applied items, got %d", len(result.Applied))
+	}
+	tests := []struct {
+		name    string
+		albumId string
+		ids     []string
+	}{
+		{"different ids", "album", append(testIds(100), "item-100")},
+		{"different album", "other", ids},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.BatchAddMediaItemsAll(tt.albumId, tt.ids, &BatchMediaItemsOptions{ResumeToken: result.ResumeToken}, context.Background())
+			if err == nil || !strings.Contains(err.Error(), "resume token doesn't match") {
+				t.Errorf("expected mismatched token error, got %v", err)
+			}
+		})
+	}
+	t.Run("same ids", func(t *testing.T) {
+		delete(server.invalidIds, "item-60")
+		resumed, err := s.BatchAddMediaItemsAll("album", ids, &BatchMediaItemsOptions{ResumeToken: result.ResumeToken}, context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(resumed.Skipped) != 99 || len(resumed.Applied) != 1 || resumed.Applied[0] != "item-60" {
+			t.Errorf("expected only failed item to be applied, got %d skipped and %v applied", len(resumed.Skipped), resumed.Applied)
+		}
+	})
+}
+
+func TestBatchAddMediaItemsAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	once := sync.Once{}
+	server := &batchServer{t: t, onRequest: func([]string) {
+		once.Do(cancel)
+	}}
+	s := NewHttpAlbumsService(test_utils.NewClient(t, server))
+	ids := testIds(500)
+	result, err := s.BatchAddMediaItemsAll("album", ids, &BatchMediaItemsOptions{Concurrency: 4}, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancelled error, got %v", err)
+	}
+	if len(result.Failed) != 0 {
+		t.Errorf("interrupted items should not be reported as failed, got %d", len(result.Failed))
+	}
+	if len(server.requests()) >= 10 {
+		t.Error("expected remaining chunks to be skipped")
+	}
+	if len(result.Pending) == 0 || len(result.Applied)+len(result.Pending) != len(ids) {
+		t.Errorf("expected unsent items to be pending, got %d applied and %d pending", len(result.Applied), len(result.Pending))
+	}
+	resumed, err := s.BatchAddMediaItemsAll("album", ids, &BatchMediaItemsOptions{ResumeToken: result.ResumeToken}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resumed.Skipped) != len(result.Applied) || len(resumed.Skipped)+len(resumed.Applied) != len(ids) {
+		t.Errorf("expected %d skipped items, got %d skipped and %d applied", len(result.Applied), len(resumed.Skipped), len(resumed.Applied))
+	}
+}
+
+func TestBatchAddMediaItemsAllRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		retries          int
+		unavailable      int32
+		invalidIds       []string
+		expectedRequests int
+		expectedFailed   int
+	}{
+		{"transient error retried", 2, 2, nil, 3, 0},
+		{"retries exhausted", 1, 2, nil, 2, 50},
+		{"no retries by default", 0, 1, nil, 1, 50},
+		// only bisection requests are made, rejected chunk is not retried
+		{"invalid argument not retried", 3, 0, []string{"item-7"}, 13, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &batchServer{t: t, invalidIds: make(map[string]bool), unavailable: tt.unavailable}
+			for _, id := range tt.invalidIds {
+				server.invalidIds[id] = true
+			}
+			s := NewHttpAlbumsService(test_utils.NewClient(t, server))
+			options := &BatchMediaItemsOptions{Retries: tt.retries, RetryDelay: time.Millisecond}
+			result, err := s.BatchAddMediaItemsAll("album", testIds(50), options, context.Background())
+			if (tt.expectedFailed > 0) != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := len(server.requests()); got != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, got)
+			}
+			if len(result.Failed) != tt.expectedFailed || len(result.Applied) != 50-tt.expectedFailed || len(result.Pending) != 0 {
+				t.Errorf("expected %d failed items, got %d applied, %d failed and %d pending", tt.expectedFailed, len(result.Applied), len(result.Failed), len(result.Pending))
+			}
+		})
+	}
+}
@@ -17,3 +17,18 @@ const (
 	AlbumFieldTitle                 Field = "title"
 	AlbumFieldCoverPhotoMediaItemId Field = "coverPhotoMediaItemId"
 )
+
+// Media item attribute used for sorting album
+type SortField string
+
+const (
+	SortByCreationTime SortField = "creationTime"
+	SortByFilename     SortField = "filename"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAscending  SortOrder = "asc"
+	SortOrderDescending SortOrder = "desc"
+)
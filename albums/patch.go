@@ -0,0 +1,64 @@
+package albums
+
+import (
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"unicode/utf8"
+)
+
+const maxTitleLength = 500
+
+// Returned by PatchDiff when there is nothing to patch
+var ErrNoChanges = internal.ErrNoChanges
+
+var patchableFields = map[string]Field{
+	string(AlbumFieldTitle):                 AlbumFieldTitle,
+	string(AlbumFieldCoverPhotoMediaItemId): AlbumFieldCoverPhotoMediaItemId,
+}
+
+// Computes update mask for Patch from fields that differ between old and new album. Returns error when fields
+// that cannot be patched were changed or new values are invalid and ErrNoChanges when nothing changed
+func PatchDiff(old Album, new Album) ([]Field, error) {
+	if old.ID != new.ID {
+		return nil, errors.New("album id cannot be changed")
+	}
+	result := make([]Field, 0)
+	for _, name := range internal.DiffFields(old, new) {
+		field, ok := patchableFields[name]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' cannot be patched", name)
+		}
+		result = append(result, field)
+	}
+	if len(result) == 0 {
+		return nil, ErrNoChanges
+	}
+	err := validatePatch(new, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validates values of fields in update mask. Empty mask means that all fields are updated
+func validatePatch(album Album, updateMask []Field) error {
+	if len(updateMask) == 0 {
+		updateMask = []Field{AlbumFieldTitle, AlbumFieldCoverPhotoMediaItemId}
+	}
+	for _, field := range updateMask {
+		switch field {
+		case AlbumFieldTitle:
+			if album.Title == "" {
+				return errors.New("title cannot be empty")
+			}
+			if utf8.RuneCountInString(album.Title) > maxTitleLength {
+				return fmt.Errorf("title cannot be longer than %d characters", maxTitleLength)
+			}
+		case AlbumFieldCoverPhotoMediaItemId:
+		default:
+			return fmt.Errorf("field '%s' cannot be patched", field)
+		}
+	}
+	return nil
+}
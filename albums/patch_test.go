@@ -0,0 +1,45 @@
+package albums
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPatchDiff(t *testing.T) {
+	old := Album{ID: "album", Title: "Title", CoverPhotoMediaItemID: "cover"}
+	tests := []struct {
+		name          string
+		new           Album
+		expected      []Field
+		expectedError string
+	}{
+		{"title", Album{ID: "album", Title: "New title", CoverPhotoMediaItemID: "cover"}, []Field{AlbumFieldTitle}, ""},
+		{"title and cover", Album{ID: "album", Title: "New title", CoverPhotoMediaItemID: "new"}, []Field{AlbumFieldTitle, AlbumFieldCoverPhotoMediaItemId}, ""},
+		{"no changes", old, nil, ErrNoChanges.Error()},
+		{"changed id", Album{ID: "other", Title: "Title"}, nil, "album id cannot be changed"},
+		{"not patchable field", Album{ID: "album", Title: "Title", CoverPhotoMediaItemID: "cover", ProductURL: "url"}, nil, "cannot be patched"},
+		{"empty title", Album{ID: "album", CoverPhotoMediaItemID: "cover"}, nil, "title cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PatchDiff(old, tt.new)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+	if _, err := PatchDiff(old, old); !errors.Is(err, ErrNoChanges) {
+		t.Errorf("expected ErrNoChanges, got %v", err)
+	}
+}
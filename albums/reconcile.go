@@ -0,0 +1,65 @@
+package albums
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+)
+
+// Makes album contain exactly media items specified by desiredMediaItemIds. Current items are listed with
+// mediaItems.search and the difference is applied with BatchRemoveMediaItemsAll and BatchAddMediaItemsAll.
+// Nothing is changed when options.PlanOnly is set
+func (s HttpAlbumsService) Reconcile(albumId string, desiredMediaItemIds []string, options *ReconcileOptions, ctx context.Context, opts ...call_options.CallOption) (*ReconcileResult, error) {
+	requestOptions := ReconcileOptions{}
+	if options != nil {
+		requestOptions = *options
+	}
+	currentItems, err := s.c.ListAlbumItems(albumId, ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list album items: %w", err)
+	}
+	current := make(map[string]struct{}, len(currentItems))
+	for _, item := range currentItems {
+		current[item.ID] = struct{}{}
+	}
+	desired := make(map[string]struct{}, len(desiredMediaItemIds))
+	result := &ReconcileResult{
+		ToAdd:    make([]string, 0),
+		ToRemove: make([]string, 0),
+	}
+	for _, id := range desiredMediaItemIds {
+		if _, ok := desired[id]; ok {
+			continue
+		}
+		desired[id] = struct{}{}
+		if _, ok := current[id]; !ok {
+			result.ToAdd = append(result.ToAdd, id)
+		}
+	}
+	for _, item := range currentItems {
+		if _, ok := desired[item.ID]; !ok {
+			result.ToRemove = append(result.ToRemove, item.ID)
+		}
+	}
+	if requestOptions.PlanOnly {
+		return result, nil
+	}
+	batchOptions := BatchMediaItemsOptions{}
+	if requestOptions.BatchOptions != nil {
+		batchOptions = *requestOptions.BatchOptions
+		batchOptions.ResumeToken = ""
+	}
+	if len(result.ToRemove) > 0 {
+		result.Removed, err = s.BatchRemoveMediaItemsAll(albumId, result.ToRemove, &batchOptions, ctx, opts...)
+		if err != nil {
+			return result, fmt.Errorf("cannot reconcile album: %w", err)
+		}
+	}
+	if len(result.ToAdd) > 0 {
+		result.Added, err = s.BatchAddMediaItemsAll(albumId, result.ToAdd, &batchOptions, ctx, opts...)
+		if err != nil {
+			return result, fmt.Errorf("cannot reconcile album: %w", err)
+		}
+	}
+	return result, nil
+}
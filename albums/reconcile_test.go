@@ -0,0 +1,94 @@
+package albums
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name             string
+		desired          []string
+		expectedToAdd    []string
+		expectedToRemove []string
+	}{
+		{"unchanged", []string{"c", "a", "b"}, []string{}, []string{}},
+		{"missing items", []string{"a", "b", "c", "d", "e"}, []string{"d", "e"}, []string{}},
+		{"extra items", []string{"b"}, []string{}, []string{"a", "c"}},
+		{"missing and extra items", []string{"a", "d", "d"}, []string{"d"}, []string{"b", "c"}},
+		{"empty album", []string{}, []string{}, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		for _, planOnly := range []bool{true, false} {
+			name := tt.name
+			if planOnly {
+				name += " (plan only)"
+			}
+			t.Run(name, func(t *testing.T) {
+				library := test_utils.NewLibrary(t)
+				// album items are listed in multiple pages
+				library.PageSize = 2
+				library.AddMediaItems(
+					test_utils.LibraryMediaItem{ID: "a"},
+					test_utils.LibraryMediaItem{ID: "b"},
+					test_utils.LibraryMediaItem{ID: "c"},
+					test_utils.LibraryMediaItem{ID: "d"},
+					test_utils.LibraryMediaItem{ID: "e"},
+				)
+				library.AddAlbums(test_utils.LibraryAlbum{ID: "album", MediaItemIds: []string{"a", "b", "c"}})
+				s := NewHttpAlbumsService(test_utils.NewClient(t, library))
+				result, err := s.Reconcile("album", tt.desired, &ReconcileOptions{PlanOnly: planOnly}, context.Background())
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(result.ToAdd, tt.expectedToAdd) {
+					t.Errorf("expected %v to be added, got %v", tt.expectedToAdd, result.ToAdd)
+				}
+				if !reflect.DeepEqual(result.ToRemove, tt.expectedToRemove) {
+					t.Errorf("expected %v to be removed, got %v", tt.expectedToRemove, result.ToRemove)
+				}
+				if planOnly {
+					if result.Added != nil || result.Removed != nil {
+						t.Errorf("expected no batch results in plan only mode, got %+v", result)
+					}
+					if got := library.Album("album").MediaItemIds; !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+						t.Errorf("album shouldn't be changed in plan only mode, got %v", got)
+					}
+					return
+				}
+				if (result.Added != nil) != (len(tt.expectedToAdd) > 0) || (result.Removed != nil) != (len(tt.expectedToRemove) > 0) {
+					t.Errorf("expected batch results only for applied changes, got %+v", result)
+				}
+				got := library.Album("album").MediaItemIds
+				sort.Strings(got)
+				desired := append([]string{}, tt.desired...)
+				sort.Strings(desired)
+				expected := make([]string, 0)
+				for _, id := range desired {
+					if len(expected) == 0 || expected[len(expected)-1] != id {
+						expected = append(expected, id)
+					}
+				}
+				if !reflect.DeepEqual(got, expected) {
+					t.Errorf("expected album to contain %v, got %v", expected, got)
+				}
+			})
+		}
+	}
+}
+
+func TestReconcileListFailure(t *testing.T) {
+	library := test_utils.NewLibrary(t)
+	library.AddAlbums(test_utils.LibraryAlbum{ID: "album"})
+	library.Fail("POST", "/v1/mediaItems:search", 500, "INTERNAL")
+	_, err := NewHttpAlbumsService(test_utils.NewClient(t, library)).Reconcile("album", []string{"a"}, nil, context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := library.CountRequests("POST", "/v1/albums/album:batchAddMediaItems"); got != 0 {
+		t.Errorf("expected no changes when album items cannot be listed, got %d add requests", got)
+	}
+}
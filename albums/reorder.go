@@ -0,0 +1,219 @@
+package albums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Lists album items and remembers their order
+func (s HttpAlbumsService) snapshot(albumId string, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, []internal.AlbumItem, error) {
+	items, err := s.c.ListAlbumItems(albumId, ctx, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot list album items: %w", err)
+	}
+	result := &AlbumSnapshot{
+		AlbumId:      albumId,
+		MediaItemIds: make([]string, 0, len(items)),
+		TakenAt:      time.Now(),
+	}
+	for _, item := range items {
+		result.MediaItemIds = append(result.MediaItemIds, item.ID)
+	}
+	return result, items, nil
+}
+
+// API doesn't allow moving items and batchAddMediaItems always appends them at the end of album. Items after
+// the longest common prefix of current and desired order are removed and added again in desired order
+func (s HttpAlbumsService) reorder(albumId string, current []string, desired []string, progress func(processed int, total int), ctx context.Context, opts ...call_options.CallOption) error {
+	commonPrefix := 0
+	for commonPrefix < len(current) && commonPrefix < len(desired) && current[commonPrefix] == desired[commonPrefix] {
+		commonPrefix++
+	}
+	toRemove := current[commonPrefix:]
+	toAdd := desired[commonPrefix:]
+	if len(toRemove) == 0 && len(toAdd) == 0 {
+		return nil
+	}
+	total := len(toRemove) + len(toAdd)
+	reportProgress := func(offset int) func(processed int, total int) {
+		return func(processed int, _ int) {
+			if progress != nil {
+				progress(offset+processed, total)
+			}
+		}
+	}
+	if len(toRemove) > 0 {
+		_, err := s.BatchRemoveMediaItemsAll(albumId, toRemove, &BatchMediaItemsOptions{
+			Progress: reportProgress(0),
+		}, ctx, opts...)
+		if err != nil {
+			return fmt.Errorf("cannot remove reordered items: %w", err)
+		}
+	}
+	// single worker keeps order of added items
+	_, err := s.BatchAddMediaItemsAll(albumId, toAdd, &BatchMediaItemsOptions{
+		Concurrency: 1,
+		Progress:    reportProgress(len(toRemove)),
+	}, ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("cannot add reordered items: %w", err)
+	}
+	return nil
+}
+
+// Moves media items to specified position keeping their order. Only app-created albums can be reordered.
+// AFTER_ENRICHMENT_ITEM position is not supported since enrichments cannot be listed. Items are removed and added
+// again so until reordering finishes they are missing from album and enrichments placed after them lose their
+// position. Returns snapshot of original order that can be passed to RestoreOrder, also when reordering fails
+func (s HttpAlbumsService) Move(albumId string, mediaItemIds []string, position AlbumPosition, options *ReorderOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error) {
+	err := position.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid album position: %w", err)
+	}
+	if position.Position == AlbumPositionTypeAfterEnrichmentItem {
+		return nil, errors.New("moving after enrichment item is not supported")
+	}
+	reorderOptions := ReorderOptions{}
+	if options != nil {
+		reorderOptions = *options
+	}
+	snapshot, _, err := s.snapshot(albumId, ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	moved := make(map[string]struct{}, len(mediaItemIds))
+	for _, id := range mediaItemIds {
+		moved[id] = struct{}{}
+	}
+	rest := make([]string, 0, len(snapshot.MediaItemIds))
+	for _, id := range snapshot.MediaItemIds {
+		if _, ok := moved[id]; !ok {
+			rest = append(rest, id)
+		}
+	}
+	if len(rest)+len(moved) != len(snapshot.MediaItemIds) {
+		return nil, errors.New("some of moved media items are not in album")
+	}
+	insertAt := 0
+	switch position.Position {
+	case AlbumPositionTypeLastInAlbum:
+		insertAt = len(rest)
+	case AlbumPositionTypeAfterMediaItem:
+		insertAt = -1
+		for i, id := range rest {
+			if id == position.RelativeMediaItemId {
+				insertAt = i + 1
+				break
+			}
+		}
+		if insertAt == -1 {
+			return nil, fmt.Errorf("relative media item '%s' is not in album or is moved", position.RelativeMediaItemId)
+		}
+	}
+	desired := make([]string, 0, len(snapshot.MediaItemIds))
+	desired = append(desired, rest[:insertAt]...)
+	desired = append(desired, internal.UniqueStrings(mediaItemIds)...)
+	desired = append(desired, rest[insertAt:]...)
+	err = s.reorder(albumId, snapshot.MediaItemIds, desired, reorderOptions.Progress, ctx, opts...)
+	if err != nil {
+		return snapshot, fmt.Errorf("cannot move media items: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Sorts app-created album by creation time or filename. Items with equal keys keep their order. Like Move it's not
+// atomic and can move enrichments. Returns snapshot of original order that can be passed to RestoreOrder, also when
+// reordering fails
+func (s HttpAlbumsService) Sort(albumId string, options SortOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error) {
+	if options.By == "" {
+		options.By = SortByCreationTime
+	}
+	if options.Order == "" {
+		options.Order = SortOrderAscending
+	}
+	snapshot, items, err := s.snapshot(albumId, ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var less func(a internal.AlbumItem, b internal.AlbumItem) bool
+	switch options.By {
+	case SortByCreationTime:
+		creationTimes := make(map[string]time.Time, len(items))
+		for _, item := range items {
+			creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+			if err != nil {
+				return nil, fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+			}
+			creationTimes[item.ID] = creationTime
+		}
+		less = func(a internal.AlbumItem, b internal.AlbumItem) bool {
+			return creationTimes[a.ID].Before(creationTimes[b.ID])
+		}
+	case SortByFilename:
+		less = func(a internal.AlbumItem, b internal.AlbumItem) bool {
+			return strings.ToLower(a.Filename) < strings.ToLower(b.Filename)
+		}
+	default:
+		return nil, fmt.Errorf("invalid sort field '%s'", options.By)
+	}
+	switch options.Order {
+	case SortOrderAscending:
+	case SortOrderDescending:
+		ascending := less
+		less = func(a internal.AlbumItem, b internal.AlbumItem) bool {
+			return ascending(b, a)
+		}
+	default:
+		return nil, fmt.Errorf("invalid sort order '%s'", options.Order)
+	}
+	sorted := make([]internal.AlbumItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	desired := make([]string, 0, len(sorted))
+	for _, item := range sorted {
+		desired = append(desired, item.ID)
+	}
+	err = s.reorder(albumId, snapshot.MediaItemIds, desired, options.Progress, ctx, opts...)
+	if err != nil {
+		return snapshot, fmt.Errorf("cannot sort album: %w", err)
+	}
+	return snapshot, nil
+}
+
+// Restores order of album from snapshot. Items missing in album (e.g. after failed reorder) are added back and items
+// added after snapshot was taken are placed at the end. Like Move it's not atomic and can move enrichments. Returns
+// snapshot of order before restoring, also when reordering fails
+func (s HttpAlbumsService) RestoreOrder(snapshot AlbumSnapshot, options *ReorderOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error) {
+	reorderOptions := ReorderOptions{}
+	if options != nil {
+		reorderOptions = *options
+	}
+	current, _, err := s.snapshot(snapshot.AlbumId, ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	inSnapshot := make(map[string]struct{}, len(snapshot.MediaItemIds))
+	for _, id := range snapshot.MediaItemIds {
+		inSnapshot[id] = struct{}{}
+	}
+	desired := make([]string, 0, len(snapshot.MediaItemIds))
+	desired = append(desired, snapshot.MediaItemIds...)
+	for _, id := range current.MediaItemIds {
+		if _, ok := inSnapshot[id]; !ok {
+			desired = append(desired, id)
+		}
+	}
+	err = s.reorder(snapshot.AlbumId, current.MediaItemIds, desired, reorderOptions.Progress, ctx, opts...)
+	if err != nil {
+		return current, fmt.Errorf("cannot restore album order: %w", err)
+	}
+	return current, nil
+}
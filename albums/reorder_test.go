@@ -0,0 +1,113 @@
+package albums
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Fake album supporting listing, adding and removing items. Adding fails when failAdd is set
+type reorderServer struct {
+	t       *testing.T
+	m       sync.Mutex
+	items   []string
+	failAdd bool
+}
+
+func (s *reorderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/mediaItems:search":
+		items := make([]map[string]string, 0, len(s.items))
+		for _, id := range s.items {
+			items = append(items, map[string]string{"id": id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"mediaItems": items})
+	case "/v1/albums/album:batchAddMediaItems":
+		if s.failAdd {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"No permission to add media items to this album.","status":"PERMISSION_DENIED"}}`))
+			return
+		}
+		body := mediaItemsRequestBody{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.items = append(s.items, body.MediaItemIds...)
+		_, _ = w.Write([]byte(`{}`))
+	case "/v1/albums/album:batchRemoveMediaItems":
+		body := mediaItemsRequestBody{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		removed := make(map[string]bool)
+		for _, id := range body.MediaItemIds {
+			removed[id] = true
+		}
+		rest := make([]string, 0, len(s.items))
+		for _, id := range s.items {
+			if !removed[id] {
+				rest = append(rest, id)
+			}
+		}
+		s.items = rest
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		s.t.Errorf("unexpected path %s", r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+func (s *reorderServer) albumItems() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string{}, s.items...)
+}
+
+func (s *reorderServer) setFailAdd(failAdd bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.failAdd = failAdd
+}
+
+func TestMoveAndRestoreOrder(t *testing.T) {
+	original := []string{"a", "b", "c", "d", "e"}
+	server := &reorderServer{t: t, items: append([]string{}, original...)}
+	s := NewHttpAlbumsService(test_utils.NewClient(t, server))
+	ctx := context.Background()
+
+	snapshot, err := s.Move("album", []string{"d"}, NewAfterMediaItemPosition("a"), nil, ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"a", "d", "b", "c", "e"}; !reflect.DeepEqual(server.albumItems(), expected) {
+		t.Errorf("expected %v after move, got %v", expected, server.albumItems())
+	}
+	if !reflect.DeepEqual(snapshot.MediaItemIds, original) {
+		t.Errorf("expected snapshot of original order, got %v", snapshot.MediaItemIds)
+	}
+
+	// failed restore removes items after common prefix without adding them back
+	server.setFailAdd(true)
+	beforeRestore, err := s.RestoreOrder(*snapshot, nil, ctx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if expected := []string{"a", "d", "b", "c", "e"}; beforeRestore == nil || !reflect.DeepEqual(beforeRestore.MediaItemIds, expected) {
+		t.Fatalf("expected snapshot of order before restore, got %v", beforeRestore)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(server.albumItems(), expected) {
+		t.Errorf("expected %v after failed restore, got %v", expected, server.albumItems())
+	}
+
+	server.setFailAdd(false)
+	_, err = s.RestoreOrder(*snapshot, nil, ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(server.albumItems(), original) {
+		t.Errorf("expected original order %v, got %v", original, server.albumItems())
+	}
+}
@@ -3,3 +3,12 @@ package albums
 type createAlbumInput struct {
 	Album Album `json:"album"`
 }
+
+type shareAlbumInput struct {
+	SharedAlbumOptions SharedAlbumOptions `json:"sharedAlbumOptions"`
+}
+
+type addEnrichmentInput struct {
+	NewEnrichmentItem NewEnrichmentItem `json:"newEnrichmentItem"`
+	AlbumPosition     AlbumPosition     `json:"albumPosition"`
+}
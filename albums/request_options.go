@@ -1,11 +1,66 @@
 package albums
 
+import (
+	"fmt"
+	"time"
+)
+
 type AlbumPosition struct {
 	Position                 AlbumPositionType `json:"position"`
 	RelativeMediaItemId      string            `json:"relativeMediaItemId,omitempty"`
 	RelativeEnrichmentItemId string            `json:"relativeEnrichmentItemId,omitempty"`
 }
 
+func NewFirstInAlbumPosition() AlbumPosition {
+	return AlbumPosition{Position: AlbumPositionTypeFirstInAlbum}
+}
+
+func NewLastInAlbumPosition() AlbumPosition {
+	return AlbumPosition{Position: AlbumPositionTypeLastInAlbum}
+}
+
+func NewAfterMediaItemPosition(mediaItemId string) AlbumPosition {
+	return AlbumPosition{
+		Position:            AlbumPositionTypeAfterMediaItem,
+		RelativeMediaItemId: mediaItemId,
+	}
+}
+
+func NewAfterEnrichmentItemPosition(enrichmentItemId string) AlbumPosition {
+	return AlbumPosition{
+		Position:                 AlbumPositionTypeAfterEnrichmentItem,
+		RelativeEnrichmentItemId: enrichmentItemId,
+	}
+}
+
+// Checks if relative item IDs match position type. Relative media item ID is required only for AFTER_MEDIA_ITEM
+// and relative enrichment item ID only for AFTER_ENRICHMENT_ITEM
+func (p AlbumPosition) Validate() error {
+	switch p.Position {
+	case AlbumPositionTypeFirstInAlbum, AlbumPositionTypeLastInAlbum:
+		if p.RelativeMediaItemId != "" || p.RelativeEnrichmentItemId != "" {
+			return fmt.Errorf("relative item ID is not allowed for position %s", p.Position)
+		}
+	case AlbumPositionTypeAfterMediaItem:
+		if p.RelativeMediaItemId == "" {
+			return fmt.Errorf("relative media item ID is required for position %s", p.Position)
+		}
+		if p.RelativeEnrichmentItemId != "" {
+			return fmt.Errorf("relative enrichment item ID is not allowed for position %s", p.Position)
+		}
+	case AlbumPositionTypeAfterEnrichmentItem:
+		if p.RelativeEnrichmentItemId == "" {
+			return fmt.Errorf("relative enrichment item ID is required for position %s", p.Position)
+		}
+		if p.RelativeMediaItemId != "" {
+			return fmt.Errorf("relative media item ID is not allowed for position %s", p.Position)
+		}
+	default:
+		return fmt.Errorf("invalid position '%s'", p.Position)
+	}
+	return nil
+}
+
 type TextEnrichment struct {
 	Text string `json:"text"`
 }
@@ -20,8 +75,8 @@ type MapEnrichment struct {
 }
 
 type LatLng struct {
-	Latitude  float32 `json:"latitude"`
-	Longitude float32 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }
 
 type Location struct {
@@ -29,13 +84,107 @@ type Location struct {
 	LatLng       LatLng `json:"latLng"`
 }
 
+// Exactly one of enrichments has to be set. Use NewTextEnrichment, NewLocationEnrichment or NewMapEnrichment
+// to create valid item
 type NewEnrichmentItem struct {
-	TextEnrichment     TextEnrichment     `json:"textEnrichment,omitempty"`
-	LocationEnrichment LocationEnrichment `json:"locationEnrichment,omitempty"`
-	MapEnrichment      MapEnrichment      `json:"mapEnrichment,omitempty"`
+	TextEnrichment     *TextEnrichment     `json:"textEnrichment,omitempty"`
+	LocationEnrichment *LocationEnrichment `json:"locationEnrichment,omitempty"`
+	MapEnrichment      *MapEnrichment      `json:"mapEnrichment,omitempty"`
+}
+
+func NewTextEnrichment(text string) NewEnrichmentItem {
+	return NewEnrichmentItem{
+		TextEnrichment: &TextEnrichment{
+			Text: text,
+		},
+	}
+}
+
+func NewLocationEnrichment(location Location) NewEnrichmentItem {
+	return NewEnrichmentItem{
+		LocationEnrichment: &LocationEnrichment{
+			Location: location,
+		},
+	}
+}
+
+func NewMapEnrichment(origin Location, destination Location) NewEnrichmentItem {
+	return NewEnrichmentItem{
+		MapEnrichment: &MapEnrichment{
+			Origin:      origin,
+			Destination: destination,
+		},
+	}
+}
+
+// Checks if exactly one enrichment is set
+func (e NewEnrichmentItem) Validate() error {
+	count := 0
+	if e.TextEnrichment != nil {
+		count++
+	}
+	if e.LocationEnrichment != nil {
+		count++
+	}
+	if e.MapEnrichment != nil {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("exactly one of text, location or map enrichment is required, got %d", count)
+	}
+	return nil
 }
 
 type SharedAlbumRequestOptions struct {
 	IsCollaborative bool `json:"isCollaborative"`
 	IsCommentable   bool `json:"isCommentable"`
 }
+
+type BatchMediaItemsOptions struct {
+	// Number of 50 items chunks sent in parallel. Defaults to 1. Order of added items is not preserved
+	// when greater than 1
+	Concurrency int
+	// How many times chunk is retried when it fails with network error, 429 or 5xx status. Defaults to 0
+	Retries int
+	// Delay before first retry of chunk, doubled with every attempt. Defaults to 1 second
+	RetryDelay time.Duration
+	// Token from previous BatchMediaItemsResult. Items applied in previous run are skipped. Token is valid only
+	// for the same album, operation and list of media item ids
+	ResumeToken string
+	// Called after each chunk with number of processed and total items
+	Progress func(processed int, total int)
+}
+
+type ReconcileOptions struct {
+	// Only compute difference between current and desired album contents without applying it
+	PlanOnly bool
+	// Options used for BatchAddMediaItemsAll and BatchRemoveMediaItemsAll calls. ResumeToken is ignored
+	BatchOptions *BatchMediaItemsOptions
+}
+
+type FindByTitleOptions struct {
+	// Match titles ignoring case
+	CaseInsensitive bool
+}
+
+type CreateAlbumOptions struct {
+	Title string
+	// Media item that is added to album and set as its cover photo
+	CoverPhotoMediaItemId string
+	// Album is shared with these options right after creation when set
+	Share *SharedAlbumOptions
+}
+
+type ReorderOptions struct {
+	// Called after each chunk with number of processed and total items. Every reordered item is counted twice
+	// (removal and addition)
+	Progress func(processed int, total int)
+}
+
+type SortOptions struct {
+	// Defaults to SortByCreationTime
+	By SortField
+	// Defaults to SortOrderAscending
+	Order    SortOrder
+	Progress func(processed int, total int)
+}
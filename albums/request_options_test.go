@@ -0,0 +1,74 @@
+package albums
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAlbumPositionValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		position AlbumPosition
+		valid    bool
+	}{
+		{"first in album", NewFirstInAlbumPosition(), true},
+		{"last in album", NewLastInAlbumPosition(), true},
+		{"after media item", NewAfterMediaItemPosition("item"), true},
+		{"after enrichment item", NewAfterEnrichmentItemPosition("enrichment"), true},
+		{"empty", AlbumPosition{}, false},
+		{"unspecified", AlbumPosition{Position: AlbumPositionTypeUnspecified}, false},
+		{"unknown", AlbumPosition{Position: "MIDDLE_OF_ALBUM"}, false},
+		{"first in album with media item", AlbumPosition{Position: AlbumPositionTypeFirstInAlbum, RelativeMediaItemId: "item"}, false},
+		{"last in album with enrichment item", AlbumPosition{Position: AlbumPositionTypeLastInAlbum, RelativeEnrichmentItemId: "enrichment"}, false},
+		{"after media item without id", NewAfterMediaItemPosition(""), false},
+		{"after media item with enrichment item", AlbumPosition{Position: AlbumPositionTypeAfterMediaItem, RelativeMediaItemId: "item", RelativeEnrichmentItemId: "enrichment"}, false},
+		{"after enrichment item without id", NewAfterEnrichmentItemPosition(""), false},
+		{"after enrichment item with media item", AlbumPosition{Position: AlbumPositionTypeAfterEnrichmentItem, RelativeMediaItemId: "item", RelativeEnrichmentItemId: "enrichment"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.position.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid: %t, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestNewEnrichmentItem(t *testing.T) {
+	krakow := Location{LocationName: "Kraków", LatLng: LatLng{Latitude: 50.0614, Longitude: 19.9366}}
+	zakopane := Location{LocationName: "Zakopane", LatLng: LatLng{Latitude: 49.2992, Longitude: 19.9496}}
+	tests := []struct {
+		name       string
+		enrichment NewEnrichmentItem
+		valid      bool
+		// JSON sent as newEnrichmentItem
+		expected string
+	}{
+		{"text", NewTextEnrichment("Day 1"), true, `{"textEnrichment":{"text":"Day 1"}}`},
+		{"location", NewLocationEnrichment(krakow), true,
+			`{"locationEnrichment":{"location":{"locationName":"Kraków","latLng":{"latitude":50.0614,"longitude":19.9366}}}}`},
+		{"map", NewMapEnrichment(krakow, zakopane), true,
+			`{"mapEnrichment":{"origin":{"locationName":"Kraków","latLng":{"latitude":50.0614,"longitude":19.9366}},"destination":{"locationName":"Zakopane","latLng":{"latitude":49.2992,"longitude":19.9496}}}}`},
+		{"empty", NewEnrichmentItem{}, false, `{}`},
+		{"text and location", NewEnrichmentItem{
+			TextEnrichment:     &TextEnrichment{Text: "Day 1"},
+			LocationEnrichment: &LocationEnrichment{Location: krakow},
+		}, false, `{"textEnrichment":{"text":"Day 1"},"locationEnrichment":{"location":{"locationName":"Kraków","latLng":{"latitude":50.0614,"longitude":19.9366}}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.enrichment.Validate()
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid: %t, got %v", tt.valid, err)
+			}
+			encoded, err := json.Marshal(tt.enrichment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(encoded) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, encoded)
+			}
+		})
+	}
+}
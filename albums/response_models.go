@@ -1,14 +1,36 @@
 package albums
 
+import "time"
+
 type Album struct {
-	ID                    string         `json:"id,omitempty"`
-	Title                 string         `json:"title"`
-	ProductURL            string         `json:"productUrl,omitempty"`
-	IsWriteable           bool           `json:"isWriteable,omitempty"`
-	ShareInfo             AlbumShareInfo `json:"shareInfo,omitempty"`
-	MediaItemsCount       string         `json:"mediaItemsCount,omitempty"`
-	CoverPhotoBaseURL     string         `json:"coverPhotoBaseUrl,omitempty"`
-	CoverPhotoMediaItemID string         `json:"coverPhotoMediaItemId,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Title       string `json:"title"`
+	ProductURL  string `json:"productUrl,omitempty"`
+	IsWriteable bool   `json:"isWriteable,omitempty"`
+	// Nil when album is not shared
+	ShareInfo *AlbumShareInfo `json:"shareInfo,omitempty"`
+	// API encodes int64 as JSON string
+	MediaItemsCount       int64  `json:"mediaItemsCount,omitempty,string"`
+	CoverPhotoBaseURL     string `json:"coverPhotoBaseUrl,omitempty"`
+	CoverPhotoMediaItemID string `json:"coverPhotoMediaItemId,omitempty"`
+}
+
+func (a Album) IsShared() bool {
+	return a.ShareInfo != nil
+}
+
+// Returns true for albums that are not shared or are shared by current user
+func (a Album) IsOwnedByMe() bool {
+	return a.ShareInfo == nil || a.ShareInfo.IsOwned
+}
+
+// Returns true when current user can add media items to album. Items can be added to owned albums and to
+// collaborative albums shared by other users
+func (a Album) CanAddItems() bool {
+	if !a.IsWriteable {
+		return false
+	}
+	return a.IsOwnedByMe() || a.ShareInfo.SharedAlbumOptions.IsCollaborative
 }
 
 type AlbumShareInfo struct {
@@ -27,3 +49,53 @@ type SharedAlbumOptions struct {
 type EnrichmentItem struct {
 	Id string `json:"id"`
 }
+
+type shareAlbumResponse struct {
+	ShareInfo AlbumShareInfo `json:"shareInfo"`
+}
+
+type addEnrichmentResponse struct {
+	EnrichmentItem EnrichmentItem `json:"enrichmentItem"`
+}
+
+type BatchMediaItemsResult struct {
+	// IDs that were added/removed
+	Applied []string
+	// IDs that were rejected by API
+	Failed []BatchMediaItemsFailure
+	// IDs that were skipped because they were applied in run specified by resume token
+	Skipped []string
+	// IDs that were not sent or whose requests were interrupted because context was cancelled
+	Pending []string
+	// Token that can be passed in BatchMediaItemsOptions to continue after failure without resending applied items
+	ResumeToken string
+}
+
+type BatchMediaItemsFailure struct {
+	MediaItemId string
+	Err         error
+}
+
+type ReconcileResult struct {
+	// IDs present in desired list but missing in album
+	ToAdd []string
+	// IDs present in album but missing in desired list
+	ToRemove []string
+	// Result of adding items. Nil in plan only mode or when there was nothing to add
+	Added *BatchMediaItemsResult
+	// Result of removing items. Nil in plan only mode or when there was nothing to remove
+	Removed *BatchMediaItemsResult
+}
+
+type CreateAlbumResult struct {
+	Album *Album
+	// Nil when album wasn't shared
+	ShareInfo *AlbumShareInfo
+}
+
+// Order of media items in album taken before reordering. Can be used to restore original order with RestoreOrder
+type AlbumSnapshot struct {
+	AlbumId      string    `json:"albumId"`
+	MediaItemIds []string  `json:"mediaItemIds"`
+	TakenAt      time.Time `json:"takenAt"`
+}
@@ -0,0 +1,129 @@
+package albums
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Collaborative album shared by other user in format returned by albums.get
+const sharedAlbumJSON = `{
+  "id": "AF1QipNeXbG0",
+  "title": "Trip",
+  "productUrl": "https://photos.google.com/lr/album/AF1QipNeXbG0",
+  "isWriteable": true,
+  "shareInfo": {
+    "sharedAlbumOptions": {
+      "isCollaborative": true,
+      "isCommentable": true
+    },
+    "shareableUrl": "https://photos.app.goo.gl/Gb2rVgCp",
+    "shareToken": "AOVP1kQ8",
+    "isJoined": true,
+    "isJoinable": true
+  },
+  "mediaItemsCount": "1234567890123",
+  "coverPhotoBaseUrl": "https://lh3.googleusercontent.com/lr/AFBm1",
+  "coverPhotoMediaItemId": "AF1QipM3"
+}`
+
+func TestAlbumJSON(t *testing.T) {
+	album := Album{}
+	err := json.Unmarshal([]byte(sharedAlbumJSON), &album)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := Album{
+		ID:          "AF1QipNeXbG0",
+		Title:       "Trip",
+		ProductURL:  "https://photos.google.com/lr/album/AF1QipNeXbG0",
+		IsWriteable: true,
+		ShareInfo: &AlbumShareInfo{
+			SharedAlbumOptions: SharedAlbumOptions{IsCollaborative: true, IsCommentable: true},
+			ShareableURL:       "https://photos.app.goo.gl/Gb2rVgCp",
+			ShareToken:         "AOVP1kQ8",
+			IsJoined:           true,
+		},
+		MediaItemsCount:       1234567890123,
+		CoverPhotoBaseURL:     "https://lh3.googleusercontent.com/lr/AFBm1",
+		CoverPhotoMediaItemID: "AF1QipM3",
+	}
+	if !reflect.DeepEqual(album, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, album)
+	}
+	encoded, err := json.Marshal(album)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"mediaItemsCount":"1234567890123"`) {
+		t.Errorf("expected count encoded as string, got %s", encoded)
+	}
+	decoded := Album{}
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("expected %+v after round trip, got %+v", expected, decoded)
+	}
+}
+
+func TestAlbumJSONWithoutOptionalFields(t *testing.T) {
+	// API omits count of empty albums and share info of albums that are not shared
+	album := Album{}
+	err := json.Unmarshal([]byte(`{"id": "a", "title": "Empty", "productUrl": "https://photos.google.com/lr/album/a", "isWriteable": true}`), &album)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if album.MediaItemsCount != 0 || album.ShareInfo != nil || album.IsShared() {
+		t.Errorf("unexpected album %+v", album)
+	}
+	encoded, err := json.Marshal(album)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"id":"a","title":"Empty","productUrl":"https://photos.google.com/lr/album/a","isWriteable":true}`; string(encoded) != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+	err = json.Unmarshal([]byte(`{"id": "a", "mediaItemsCount": "many"}`), &album)
+	if err == nil {
+		t.Error("expected error for invalid count")
+	}
+}
+
+func TestAlbumSharingHelpers(t *testing.T) {
+	shareInfo := func(isOwned bool, isCollaborative bool) *AlbumShareInfo {
+		return &AlbumShareInfo{
+			SharedAlbumOptions: SharedAlbumOptions{IsCollaborative: isCollaborative},
+			IsOwned:            isOwned,
+		}
+	}
+	tests := []struct {
+		name                string
+		album               Album
+		expectedIsShared    bool
+		expectedIsOwnedByMe bool
+		expectedCanAddItems bool
+	}{
+		{"not shared", Album{IsWriteable: true}, false, true, true},
+		{"not writeable", Album{}, false, true, false},
+		{"shared by me", Album{IsWriteable: true, ShareInfo: shareInfo(true, false)}, true, true, true},
+		{"shared by other user", Album{IsWriteable: true, ShareInfo: shareInfo(false, false)}, true, false, false},
+		{"collaborative shared by other user", Album{IsWriteable: true, ShareInfo: shareInfo(false, true)}, true, false, true},
+		{"not writeable collaborative", Album{ShareInfo: shareInfo(false, true)}, true, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.album.IsShared(); got != tt.expectedIsShared {
+				t.Errorf("expected IsShared %t, got %t", tt.expectedIsShared, got)
+			}
+			if got := tt.album.IsOwnedByMe(); got != tt.expectedIsOwnedByMe {
+				t.Errorf("expected IsOwnedByMe %t, got %t", tt.expectedIsOwnedByMe, got)
+			}
+			if got := tt.album.CanAddItems(); got != tt.expectedCanAddItems {
+				t.Errorf("expected CanAddItems %t, got %t", tt.expectedCanAddItems, got)
+			}
+		})
+	}
+}
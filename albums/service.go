@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
 	"github.com/duffpl/google-photos-api-client/internal"
 	"github.com/imdario/mergo"
-	"math"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,19 +15,26 @@ import (
 
 // Interface for https://developers.google.com/photos/library/reference/rest/v1/albums resource
 type AlbumsService interface {
-	AddEnrichment(albumId string, enrichment NewEnrichmentItem, ctx context.Context) (*EnrichmentItem, error)
-	BatchAddMediaItems(albumId string, mediaItemIds []string, ctx context.Context) error
-	BatchAddMediaItemsAll(albumId string, mediaItemIds []string, ctx context.Context) error
-	BatchRemoveMediaItems(albumId string, mediaItemIds []string, ctx context.Context) error
-	BatchRemoveMediaItemsAll(albumId string, mediaItemIds []string, ctx context.Context) error
-	Create(title string, ctx context.Context) (*Album, error)
-	Get(id string, ctx context.Context) (*Album, error)
-	List(options *AlbumsListOptions, pageToken string, ctx context.Context) (result []Album, nextPageToken string, err error)
-	ListAll(options *AlbumsListOptions, ctx context.Context) ([]Album, error)
-	ListAllAsync(options *AlbumsListOptions, ctx context.Context) (<-chan Album, <-chan error)
-	Patch(album Album, fieldMask []Field, ctx context.Context) (*Album, error)
-	Share(id string, options SharedAlbumOptions, ctx context.Context) (*AlbumShareInfo, error)
-	Unshare(id string, ctx context.Context) error
+	AddEnrichment(albumId string, enrichment NewEnrichmentItem, position AlbumPosition, ctx context.Context, opts ...call_options.CallOption) (*EnrichmentItem, error)
+	BatchAddMediaItems(albumId string, mediaItemIds []string, ctx context.Context, opts ...call_options.CallOption) error
+	BatchAddMediaItemsAll(albumId string, mediaItemIds []string, options *BatchMediaItemsOptions, ctx context.Context, opts ...call_options.CallOption) (*BatchMediaItemsResult, error)
+	BatchRemoveMediaItems(albumId string, mediaItemIds []string, ctx context.Context, opts ...call_options.CallOption) error
+	BatchRemoveMediaItemsAll(albumId string, mediaItemIds []string, options *BatchMediaItemsOptions, ctx context.Context, opts ...call_options.CallOption) (*BatchMediaItemsResult, error)
+	Create(title string, ctx context.Context, opts ...call_options.CallOption) (*Album, error)
+	CreateAlbum(options CreateAlbumOptions, ctx context.Context, opts ...call_options.CallOption) (*CreateAlbumResult, error)
+	FindByTitle(title string, options *FindByTitleOptions, ctx context.Context, opts ...call_options.CallOption) ([]Album, error)
+	Get(id string, ctx context.Context, opts ...call_options.CallOption) (*Album, error)
+	GetOrCreate(title string, ctx context.Context, opts ...call_options.CallOption) (*Album, error)
+	List(options *AlbumsListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (result []Album, nextPageToken string, err error)
+	ListAll(options *AlbumsListOptions, ctx context.Context, opts ...call_options.CallOption) ([]Album, error)
+	ListAllAsync(options *AlbumsListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan Album, <-chan error)
+	Move(albumId string, mediaItemIds []string, position AlbumPosition, options *ReorderOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error)
+	Patch(album Album, fieldMask []Field, ctx context.Context, opts ...call_options.CallOption) (*Album, error)
+	Reconcile(albumId string, desiredMediaItemIds []string, options *ReconcileOptions, ctx context.Context, opts ...call_options.CallOption) (*ReconcileResult, error)
+	RestoreOrder(snapshot AlbumSnapshot, options *ReorderOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error)
+	Share(id string, options SharedAlbumOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumShareInfo, error)
+	Sort(albumId string, options SortOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumSnapshot, error)
+	Unshare(id string, ctx context.Context, opts ...call_options.CallOption) error
 }
 
 type AlbumsListOptions struct {
@@ -44,78 +52,83 @@ type mediaItemsRequestBody struct {
 }
 
 type HttpAlbumsService struct {
-	c    *internal.HttpClient
-	path string
+	c          *internal.HttpClient
+	path       string
+	titleIndex *titleIndex
 }
 
-// Adds enrichment item to album specified by id
+// Adds enrichment item to album specified by id at specified position
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/addEnrichment
-func (s HttpAlbumsService) AddEnrichment(albumId string, enrichment NewEnrichmentItem, ctx context.Context) (*EnrichmentItem, error) {
-	responseModel := &EnrichmentItem{}
-	err := s.c.PostJSON(s.path+"/"+albumId+":addEnrichment", nil, enrichment, responseModel, nil, ctx)
+func (s HttpAlbumsService) AddEnrichment(albumId string, enrichment NewEnrichmentItem, position AlbumPosition, ctx context.Context, opts ...call_options.CallOption) (*EnrichmentItem, error) {
+	err := enrichment.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid enrichment: %w", err)
+	}
+	err = position.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid album position: %w", err)
+	}
+	responseModel := &addEnrichmentResponse{}
+	body := addEnrichmentInput{
+		NewEnrichmentItem: enrichment,
+		AlbumPosition:     position,
+	}
+	err = s.c.PostJSON(s.path+"/"+albumId+":addEnrichment", nil, body, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot add enrichment: %w", err)
 	}
-	return responseModel, nil
+	return &responseModel.EnrichmentItem, nil
 }
 
 // Removes multiple media items (max 50) from album specified by id
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/batchRemoveMediaItems
-func (s HttpAlbumsService) BatchRemoveMediaItems(albumId string, mediaItemIds []string, ctx context.Context) error {
+func (s HttpAlbumsService) BatchRemoveMediaItems(albumId string, mediaItemIds []string, ctx context.Context, opts ...call_options.CallOption) error {
 	if len(mediaItemIds) > 50 {
 		return errors.New("maximum allowed IDs is 50")
 	}
 	body := mediaItemsRequestBody{mediaItemIds}
-	err := s.c.PostJSON(s.path+"/"+albumId+":batchRemoveMediaItems", nil, body, nil, nil, ctx)
+	err := s.c.PostJSON(s.path+"/"+albumId+":batchRemoveMediaItems", nil, body, nil, nil, ctx, internal.Idempotent(opts)...)
 	if err != nil {
 		return fmt.Errorf("cannot batch remove media items: %w", err)
 	}
 	return nil
 }
 
-// Removes multiple media items (no limit) using multiple BatchRemoveMediaItems requests
-func (s HttpAlbumsService) BatchRemoveMediaItemsAll(albumId string, mediaItemIds []string, ctx context.Context) error {
-	itemsPerRequest := 50
-	requestCount := int(math.Ceil(float64(len(mediaItemIds)) / float64(itemsPerRequest)))
-	idsCount := len(mediaItemIds)
-	for i := 0; i < requestCount; i++ {
-		startOffset := i * itemsPerRequest
-		endOffset := startOffset + internal.Min(idsCount-startOffset, itemsPerRequest)
-		err := s.BatchRemoveMediaItems(albumId, mediaItemIds[startOffset:endOffset], ctx)
-		if err != nil {
-			return fmt.Errorf("cannot batch remove all media items: %w", err)
-		}
+// Removes multiple media items (no limit) using multiple BatchRemoveMediaItems requests. Processing doesn't stop
+// at first failed chunk - result lists applied, failed and skipped items. Returned error is non-nil when any item failed
+func (s HttpAlbumsService) BatchRemoveMediaItemsAll(albumId string, mediaItemIds []string, options *BatchMediaItemsOptions, ctx context.Context, opts ...call_options.CallOption) (*BatchMediaItemsResult, error) {
+	result, err := batchMediaItemsAll(albumId, mediaItemIds, batchOperationRemove, options, func(ids []string, ctx context.Context) error {
+		return s.BatchRemoveMediaItems(albumId, ids, ctx, opts...)
+	}, ctx)
+	if err != nil {
+		return result, fmt.Errorf("cannot batch remove all media items: %w", err)
 	}
-	return nil
+	return result, nil
 }
 
-// Adds multiple media items (no limit) using multiple BatchAddMediaItems requests
-func (s HttpAlbumsService) BatchAddMediaItemsAll(albumId string, mediaItemIds []string, ctx context.Context) error {
-	itemsPerRequest := 50
-	requestCount := int(math.Ceil(float64(len(mediaItemIds)) / float64(itemsPerRequest)))
-	idsCount := len(mediaItemIds)
-	for i := 0; i < requestCount; i++ {
-		startOffset := i * itemsPerRequest
-		endOffset := startOffset + internal.Min(idsCount-startOffset, itemsPerRequest)
-		err := s.BatchAddMediaItems(albumId, mediaItemIds[startOffset:endOffset], ctx)
-		if err != nil {
-			return fmt.Errorf("cannot batch add all media items: %w", err)
-		}
+// Adds multiple media items (no limit) using multiple BatchAddMediaItems requests. Processing doesn't stop
+// at first failed chunk - result lists applied, failed and skipped items. Returned error is non-nil when any item failed
+func (s HttpAlbumsService) BatchAddMediaItemsAll(albumId string, mediaItemIds []string, options *BatchMediaItemsOptions, ctx context.Context, opts ...call_options.CallOption) (*BatchMediaItemsResult, error) {
+	result, err := batchMediaItemsAll(albumId, mediaItemIds, batchOperationAdd, options, func(ids []string, ctx context.Context) error {
+		return s.BatchAddMediaItems(albumId, ids, ctx, opts...)
+	}, ctx)
+	if err != nil {
+		return result, fmt.Errorf("cannot batch add all media items: %w", err)
 	}
-	return nil
+	return result, nil
 }
 
 // Adds multiple media items (max 50) to album specified by id
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/batchAddMediaItems
-func (s HttpAlbumsService) BatchAddMediaItems(albumId string, mediaItemIds []string, ctx context.Context) error {
+func (s HttpAlbumsService) BatchAddMediaItems(albumId string, mediaItemIds []string, ctx context.Context, opts ...call_options.CallOption) error {
 	if len(mediaItemIds) > 50 {
 		return errors.New("maximum allowed IDs is 50")
 	}
 	body := mediaItemsRequestBody{mediaItemIds}
-	err := s.c.PostJSON(s.path+"/"+albumId+":batchAddMediaItems", nil, body, nil, nil, ctx)
+	err := s.c.PostJSON(s.path+"/"+albumId+":batchAddMediaItems", nil, body, nil, nil, ctx, internal.Idempotent(opts)...)
 	if err != nil {
 		return fmt.Errorf("cannot batch add media items: %w", err)
 	}
@@ -125,8 +138,8 @@ func (s HttpAlbumsService) BatchAddMediaItems(albumId string, mediaItemIds []str
 // Unshares album specified by id
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/unshare
-func (s HttpAlbumsService) Unshare(id string, ctx context.Context) error {
-	err := s.c.PostJSON(s.path+"/"+id+":unshare", nil, nil, nil, nil, ctx)
+func (s HttpAlbumsService) Unshare(id string, ctx context.Context, opts ...call_options.CallOption) error {
+	err := s.c.PostJSON(s.path+"/"+id+":unshare", nil, nil, nil, nil, ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("cannot unshare album: %w", err)
 	}
@@ -136,37 +149,98 @@ func (s HttpAlbumsService) Unshare(id string, ctx context.Context) error {
 // Shares album specified by id
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/share
-func (s HttpAlbumsService) Share(id string, options SharedAlbumOptions, ctx context.Context) (*AlbumShareInfo, error) {
-	responseModel := &AlbumShareInfo{}
-	err := s.c.PostJSON(s.path+"/"+id+":share", nil, options, responseModel, nil, ctx)
+func (s HttpAlbumsService) Share(id string, options SharedAlbumOptions, ctx context.Context, opts ...call_options.CallOption) (*AlbumShareInfo, error) {
+	responseModel := &shareAlbumResponse{}
+	body := shareAlbumInput{
+		SharedAlbumOptions: options,
+	}
+	err := s.c.PostJSON(s.path+"/"+id+":share", nil, body, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot share album: %w", err)
 	}
-	return responseModel, nil
+	return &responseModel.ShareInfo, nil
 }
 
 // Create new album
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/create
-func (s HttpAlbumsService) Create(title string, ctx context.Context) (*Album, error) {
+func (s HttpAlbumsService) Create(title string, ctx context.Context, opts ...call_options.CallOption) (*Album, error) {
+	defer s.invalidateTitleIndex()
 	responseModel := &Album{}
 	err := s.c.PostJSON(s.path, nil, createAlbumInput{
 		Album: Album{
 			Title: title,
 		},
-	}, responseModel, nil, ctx)
+	}, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create album: %w", err)
 	}
 	return responseModel, nil
 }
 
+// Creates new album with cover photo and share settings. Album is shared right after creation, then cover media
+// item is added and set as cover photo. When setting cover fails added item is removed and album is unshared. Album
+// cannot be deleted through API so on failure result contains created album. Fields call option is ignored as album ID is needed by
+// following requests
+func (s HttpAlbumsService) CreateAlbum(options CreateAlbumOptions, ctx context.Context, opts ...call_options.CallOption) (*CreateAlbumResult, error) {
+	opts = internal.WithoutFields(opts)
+	album, err := s.Create(options.Title, ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	result := &CreateAlbumResult{
+		Album: album,
+	}
+	if options.Share != nil {
+		result.ShareInfo, err = s.Share(album.ID, *options.Share, ctx, opts...)
+		if err != nil {
+			return result, fmt.Errorf("album '%s' created but not shared: %w", album.ID, err)
+		}
+		album.ShareInfo = result.ShareInfo
+	}
+	if options.CoverPhotoMediaItemId == "" {
+		return result, nil
+	}
+	err = s.setCoverPhoto(album, options.CoverPhotoMediaItemId, ctx, opts...)
+	if err == nil {
+		return result, nil
+	}
+	if result.ShareInfo != nil {
+		unshareErr := s.Unshare(album.ID, ctx, opts...)
+		if unshareErr != nil {
+			return result, fmt.Errorf("cannot set cover photo of album '%s': %v, rollback failed: %w", album.ID, err, unshareErr)
+		}
+		result.ShareInfo = nil
+		album.ShareInfo = nil
+	}
+	return result, fmt.Errorf("cannot set cover photo of album '%s': %w", album.ID, err)
+}
+
+func (s HttpAlbumsService) setCoverPhoto(album *Album, mediaItemId string, ctx context.Context, opts ...call_options.CallOption) error {
+	err := s.BatchAddMediaItems(album.ID, []string{mediaItemId}, ctx, opts...)
+	if err != nil {
+		return err
+	}
+	updated := *album
+	updated.CoverPhotoMediaItemID = mediaItemId
+	patched, err := s.Patch(updated, []Field{AlbumFieldCoverPhotoMediaItemId}, ctx, opts...)
+	if err != nil {
+		removeErr := s.BatchRemoveMediaItems(album.ID, []string{mediaItemId}, ctx, opts...)
+		if removeErr != nil {
+			return fmt.Errorf("%v, cannot remove cover media item: %w", err, removeErr)
+		}
+		return err
+	}
+	*album = *patched
+	return nil
+}
+
 // Fetch album by id
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/get
-func (s HttpAlbumsService) Get(id string, ctx context.Context) (*Album, error) {
+func (s HttpAlbumsService) Get(id string, ctx context.Context, opts ...call_options.CallOption) (*Album, error) {
 	responseModel := &Album{}
-	err := s.c.FetchWithGet(s.path+"/"+id, nil, responseModel, nil, ctx)
+	err := s.c.FetchWithGet(s.path+"/"+id, nil, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch album: %w", err)
 	}
@@ -176,7 +250,7 @@ func (s HttpAlbumsService) Get(id string, ctx context.Context) (*Album, error) {
 // Lists all albums
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/list
-func (s HttpAlbumsService) List(options *AlbumsListOptions, pageToken string, ctx context.Context) (result []Album, nextPageToken string, err error) {
+func (s HttpAlbumsService) List(options *AlbumsListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (result []Album, nextPageToken string, err error) {
 	requestOptions := AlbumsListOptions{
 		PageSize: 50,
 	}
@@ -191,7 +265,7 @@ func (s HttpAlbumsService) List(options *AlbumsListOptions, pageToken string, ct
 		pageToken,
 	}
 	responseModel := &getAlbumsResponse{}
-	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx)
+	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx, internal.RequireFields(opts, "nextPageToken")...)
 	if err != nil {
 		return nil, "", fmt.Errorf("cannot process request: %w", err)
 	}
@@ -199,8 +273,8 @@ func (s HttpAlbumsService) List(options *AlbumsListOptions, pageToken string, ct
 }
 
 // Synchronous wrapper for ListAllAsync
-func (s HttpAlbumsService) ListAll(options *AlbumsListOptions, ctx context.Context) ([]Album, error) {
-	albumsC, errorsC := s.ListAllAsync(options, ctx)
+func (s HttpAlbumsService) ListAll(options *AlbumsListOptions, ctx context.Context, opts ...call_options.CallOption) ([]Album, error) {
+	albumsC, errorsC := s.ListAllAsync(options, ctx, opts...)
 	result := make([]Album, 0)
 	for {
 		select {
@@ -216,7 +290,7 @@ func (s HttpAlbumsService) ListAll(options *AlbumsListOptions, ctx context.Conte
 }
 
 // Asynchronous wrapper for List that takes care of pagination. Returned channel has buffer size of 50
-func (s HttpAlbumsService) ListAllAsync(options *AlbumsListOptions, ctx context.Context) (<-chan Album, <-chan error) {
+func (s HttpAlbumsService) ListAllAsync(options *AlbumsListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan Album, <-chan error) {
 	albumsC := make(chan Album, 50)
 	errorsC := make(chan error)
 	pageToken := ""
@@ -228,7 +302,7 @@ func (s HttpAlbumsService) ListAllAsync(options *AlbumsListOptions, ctx context.
 				return
 			default:
 			}
-			items, token, err := s.List(options, pageToken, ctx)
+			items, token, err := s.List(options, pageToken, ctx, opts...)
 			if err != nil {
 				errorsC <- err
 				return
@@ -249,11 +323,16 @@ func (s HttpAlbumsService) ListAllAsync(options *AlbumsListOptions, ctx context.
 	return albumsC, errorsC
 }
 
-// Patches album. updateMask argument can be used to update only selected fields. Currently only id, title
-// and coverPhotoMediaItemId are read
+// Patches album. updateMask argument can be used to update only selected fields (see PatchDiff). Currently only
+// id, title and coverPhotoMediaItemId are read
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/albums/patch
-func (s HttpAlbumsService) Patch(album Album, updateMask []Field, ctx context.Context) (*Album, error) {
+func (s HttpAlbumsService) Patch(album Album, updateMask []Field, ctx context.Context, opts ...call_options.CallOption) (*Album, error) {
+	defer s.invalidateTitleIndex()
+	err := validatePatch(album, updateMask)
+	if err != nil {
+		return nil, fmt.Errorf("invalid album patch: %w", err)
+	}
 	responseModel := &Album{}
 	queryValues := url.Values{}
 	if len(updateMask) > 0 {
@@ -263,16 +342,16 @@ func (s HttpAlbumsService) Patch(album Album, updateMask []Field, ctx context.Co
 		}
 		queryValues["updateMask"] = []string{strings.Join(fields, ",")}
 	}
-	err := s.c.PatchJSON(s.path+"/"+album.ID, queryValues, album, responseModel, nil, ctx)
+	err = s.c.PatchJSON(s.path+"/"+album.ID, queryValues, album, responseModel, nil, ctx, internal.Idempotent(opts)...)
 	if err != nil {
 		return nil, err
 	}
 	return responseModel, nil
 }
 
-func NewHttpAlbumsService(authenticatedClient *http.Client) HttpAlbumsService {
+func NewHttpAlbumsService(authenticatedClient *http.Client, options ...client_options.ClientOption) HttpAlbumsService {
 	return HttpAlbumsService{
-		c:    internal.NewHttpClient(authenticatedClient),
+		c:    internal.NewHttpClient(authenticatedClient, options...),
 		path: "v1/albums",
 	}
 }
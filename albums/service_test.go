@@ -0,0 +1,159 @@
+package albums
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestListAllWithFields(t *testing.T) {
+	pages := map[string]getAlbumsResponse{
+		"":      {Albums: []Album{{ID: "a1"}}, NextPageToken: "page2"},
+		"page2": {Albums: []Album{{ID: "a2"}}},
+	}
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fields := r.URL.Query().Get("fields"); fields != "albums(id),nextPageToken" {
+			t.Errorf("unexpected fields %q", fields)
+		}
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("pageToken")])
+	}))
+	result, err := NewHttpAlbumsService(client).ListAll(nil, context.Background(), call_options.WithFields("albums(id)"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0].ID != "a1" || result[1].ID != "a2" {
+		t.Errorf("unexpected albums: %+v", result)
+	}
+}
+
+func TestShare(t *testing.T) {
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/albums/album:share" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"sharedAlbumOptions":{"isCollaborative":true,"isCommentable":false}}` {
+			t.Errorf("unexpected body %s", body)
+		}
+		_, _ = w.Write([]byte(`{"shareInfo":{"sharedAlbumOptions":{"isCollaborative":true},"shareableUrl":"https://photos.app.goo.gl/short","shareToken":"token","isJoined":true,"isOwned":true,"isJoinable":true}}`))
+	}))
+	shareInfo, err := NewHttpAlbumsService(client).Share("album", SharedAlbumOptions{IsCollaborative: true}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shareInfo.ShareToken != "token" || shareInfo.ShareableURL != "https://photos.app.goo.gl/short" || !shareInfo.SharedAlbumOptions.IsCollaborative || !shareInfo.IsOwned {
+		t.Errorf("unexpected share info %+v", shareInfo)
+	}
+}
+
+func TestCreateAlbum(t *testing.T) {
+	const albumPath = "/v1/albums/created-1"
+	tests := []struct {
+		name  string
+		share bool
+		// method and path of failed request
+		failures [][2]string
+		// error, album shared, cover item in album
+		expectedError  bool
+		expectedShared bool
+		expectedItems  []string
+		expectedCover  string
+	}{
+		{"shared with cover", true, nil, false, true, []string{"cover"}, "cover"},
+		{"not shared", false, nil, false, false, []string{"cover"}, "cover"},
+		{"share fails", true, [][2]string{{"POST", albumPath + ":share"}}, true, false, nil, ""},
+		{"adding cover item fails", true, [][2]string{{"POST", albumPath + ":batchAddMediaItems"}}, true, false, nil, ""},
+		{"patch fails", true, [][2]string{{"PATCH", albumPath}}, true, false, []string{}, ""},
+		{"patch of not shared album fails", false, [][2]string{{"PATCH", albumPath}}, true, false, []string{}, ""},
+		{"patch and unshare fail", true, [][2]string{{"PATCH", albumPath}, {"POST", albumPath + ":unshare"}}, true, true, []string{}, ""},
+		{"patch and removing cover item fail", true, [][2]string{{"PATCH", albumPath}, {"POST", albumPath + ":batchRemoveMediaItems"}}, true, false, []string{"cover"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			library := test_utils.NewLibrary(t)
+			library.AddMediaItems(test_utils.LibraryMediaItem{ID: "cover"})
+			for _, failure := range tt.failures {
+				library.Fail(failure[0], failure[1], 500, "INTERNAL")
+			}
+			options := CreateAlbumOptions{
+				Title:                 "Album",
+				CoverPhotoMediaItemId: "cover",
+			}
+			if tt.share {
+				options.Share = &SharedAlbumOptions{IsCollaborative: true}
+			}
+			result, err := NewHttpAlbumsService(test_utils.NewClient(t, library)).CreateAlbum(options, context.Background())
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error: %t, got %v", tt.expectedError, err)
+			}
+			if result == nil || result.Album == nil || result.Album.ID != "created-1" {
+				t.Fatalf("expected result with created album, got %+v", result)
+			}
+			album := library.Album("created-1")
+			if (album.ShareInfo != nil) != tt.expectedShared || (result.ShareInfo != nil) != tt.expectedShared {
+				t.Errorf("expected album shared: %t, got %v (result %+v)", tt.expectedShared, album.ShareInfo, result.ShareInfo)
+			}
+			if len(album.MediaItemIds) != len(tt.expectedItems) || (len(tt.expectedItems) > 0 && album.MediaItemIds[0] != tt.expectedItems[0]) {
+				t.Errorf("expected album items %v, got %v", tt.expectedItems, album.MediaItemIds)
+			}
+			if album.CoverPhotoMediaItemId != tt.expectedCover || result.Album.CoverPhotoMediaItemID != tt.expectedCover {
+				t.Errorf("expected cover '%s', got '%s' (result '%s')", tt.expectedCover, album.CoverPhotoMediaItemId, result.Album.CoverPhotoMediaItemID)
+			}
+			if !tt.share && library.CountRequests("POST", albumPath+":unshare") != 0 {
+				t.Error("album that wasn't shared shouldn't be unshared")
+			}
+		})
+	}
+}
+
+func TestAddEnrichment(t *testing.T) {
+	tests := []struct {
+		name       string
+		enrichment NewEnrichmentItem
+		position   AlbumPosition
+		// empty when request shouldn't be sent
+		expectedBody string
+	}{
+		{"after media item", NewTextEnrichment("Day 1"), NewAfterMediaItemPosition("item"),
+			`{"newEnrichmentItem":{"textEnrichment":{"text":"Day 1"}},"albumPosition":{"position":"AFTER_MEDIA_ITEM","relativeMediaItemId":"item"}}`},
+		{"first in album", NewLocationEnrichment(Location{LocationName: "Kraków", LatLng: LatLng{Latitude: 50.0614, Longitude: 19.9366}}), NewFirstInAlbumPosition(),
+			`{"newEnrichmentItem":{"locationEnrichment":{"location":{"locationName":"Kraków","latLng":{"latitude":50.0614,"longitude":19.9366}}}},"albumPosition":{"position":"FIRST_IN_ALBUM"}}`},
+		{"after enrichment item", NewTextEnrichment("Day 2"), NewAfterEnrichmentItemPosition("enrichment"),
+			`{"newEnrichmentItem":{"textEnrichment":{"text":"Day 2"}},"albumPosition":{"position":"AFTER_ENRICHMENT_ITEM","relativeEnrichmentItemId":"enrichment"}}`},
+		{"invalid enrichment", NewEnrichmentItem{}, NewLastInAlbumPosition(), ""},
+		{"invalid position", NewTextEnrichment("Day 1"), AlbumPosition{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/albums/album:addEnrichment" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				if string(body) != tt.expectedBody {
+					t.Errorf("expected body %s, got %s", tt.expectedBody, body)
+				}
+				_, _ = w.Write([]byte(`{"enrichmentItem":{"id":"created"}}`))
+			}))
+			item, err := NewHttpAlbumsService(client).AddEnrichment("album", tt.enrichment, tt.position, context.Background())
+			if tt.expectedBody == "" {
+				if err == nil || requests != 0 {
+					t.Errorf("expected validation error without request, got %v (%d requests)", err, requests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if item.Id != "created" {
+				t.Errorf("unexpected enrichment item %+v", item)
+			}
+		})
+	}
+}
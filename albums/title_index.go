@@ -0,0 +1,107 @@
+package albums
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"strings"
+	"sync"
+)
+
+// In-memory index of albums by title. Loaded lazily with ListAll and invalidated by Create and Patch
+type titleIndex struct {
+	m       sync.Mutex
+	loaded  bool
+	albums  []Album
+	byTitle map[string][]Album
+}
+
+func (i *titleIndex) invalidate() {
+	i.m.Lock()
+	defer i.m.Unlock()
+	i.loaded = false
+	i.albums = nil
+	i.byTitle = nil
+}
+
+// Returns albums with exactly matching title or all albums when title is empty
+func (i *titleIndex) get(title string, load func() ([]Album, error)) ([]Album, error) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	if !i.loaded {
+		albums, err := load()
+		if err != nil {
+			return nil, err
+		}
+		i.albums = albums
+		i.byTitle = make(map[string][]Album)
+		for _, album := range albums {
+			i.byTitle[album.Title] = append(i.byTitle[album.Title], album)
+		}
+		i.loaded = true
+	}
+	if title == "" {
+		return i.albums, nil
+	}
+	return i.byTitle[title], nil
+}
+
+// Returns copy of service that uses in-memory title index for FindByTitle and GetOrCreate. Index is shared
+// between copies of returned service
+func (s HttpAlbumsService) WithTitleIndex() HttpAlbumsService {
+	s.titleIndex = &titleIndex{}
+	return s
+}
+
+func (s HttpAlbumsService) invalidateTitleIndex() {
+	if s.titleIndex != nil {
+		s.titleIndex.invalidate()
+	}
+}
+
+// Finds albums with specified title. All albums are listed with ListAll unless title index is enabled
+// (see WithTitleIndex). Fields call option is ignored so returned albums are complete
+func (s HttpAlbumsService) FindByTitle(title string, options *FindByTitleOptions, ctx context.Context, opts ...call_options.CallOption) ([]Album, error) {
+	findOptions := FindByTitleOptions{}
+	if options != nil {
+		findOptions = *options
+	}
+	var candidates []Album
+	var err error
+	if s.titleIndex != nil {
+		// case-insensitive search has to check all titles
+		indexKey := title
+		if findOptions.CaseInsensitive {
+			indexKey = ""
+		}
+		candidates, err = s.titleIndex.get(indexKey, func() ([]Album, error) {
+			return s.ListAll(nil, ctx, internal.WithoutFields(opts)...)
+		})
+	} else {
+		candidates, err = s.ListAll(nil, ctx, internal.WithoutFields(opts)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot list albums: %w", err)
+	}
+	result := make([]Album, 0)
+	for _, album := range candidates {
+		if album.Title == title || (findOptions.CaseInsensitive && strings.EqualFold(album.Title, title)) {
+			result = append(result, album)
+		}
+	}
+	return result, nil
+}
+
+// Returns first album with exactly matching title or creates new one when it doesn't exist. Lookup and creation
+// are separate requests so concurrent calls with the same title can create duplicated albums
+func (s HttpAlbumsService) GetOrCreate(title string, ctx context.Context, opts ...call_options.CallOption) (*Album, error) {
+	existing, err := s.FindByTitle(title, nil, ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find album: %w", err)
+	}
+	if len(existing) > 0 {
+		return &existing[0], nil
+	}
+	return s.Create(title, ctx, opts...)
+}
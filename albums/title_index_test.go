@@ -0,0 +1,105 @@
+package albums
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"reflect"
+	"testing"
+)
+
+func newTitleIndexLibrary(t *testing.T) *test_utils.Library {
+	library := test_utils.NewLibrary(t)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "trip", Title: "Trip"},
+		test_utils.LibraryAlbum{ID: "trip-lower", Title: "trip"},
+		test_utils.LibraryAlbum{ID: "party", Title: "Party"},
+	)
+	// title lookups have to go through all pages
+	library.PageSize = 2
+	return library
+}
+
+func albumIds(albums []Album) []string {
+	result := make([]string, 0, len(albums))
+	for _, album := range albums {
+		result = append(result, album.ID)
+	}
+	return result
+}
+
+func TestFindByTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		options  *FindByTitleOptions
+		expected []string
+	}{
+		{"exact", "Trip", nil, []string{"trip"}},
+		{"case insensitive", "TRIP", &FindByTitleOptions{CaseInsensitive: true}, []string{"trip", "trip-lower"}},
+		{"last page", "Party", nil, []string{"party"}},
+		{"missing", "Missing", nil, []string{}},
+	}
+	for _, withIndex := range []bool{false, true} {
+		for _, tt := range tests {
+			name := tt.name
+			if withIndex {
+				name += " with index"
+			}
+			t.Run(name, func(t *testing.T) {
+				s := NewHttpAlbumsService(test_utils.NewClient(t, newTitleIndexLibrary(t)))
+				if withIndex {
+					s = s.WithTitleIndex()
+				}
+				result, err := s.FindByTitle(tt.title, tt.options, context.Background())
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := albumIds(result); !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			})
+		}
+	}
+}
+
+func TestTitleIndex(t *testing.T) {
+	library := newTitleIndexLibrary(t)
+	s := NewHttpAlbumsService(test_utils.NewClient(t, library)).WithTitleIndex()
+	// index is shared between copies of service
+	sCopy := s
+	listRequests := func() int {
+		// 3 albums are listed in 2 pages
+		return library.CountRequests("GET", "/v1/albums") / 2
+	}
+	_, _ = s.FindByTitle("Trip", nil, context.Background())
+	_, _ = sCopy.FindByTitle("Party", &FindByTitleOptions{CaseInsensitive: true}, context.Background())
+	if got := listRequests(); got != 1 {
+		t.Fatalf("expected albums to be listed once, got %d", got)
+	}
+	existing, err := s.GetOrCreate("Party", context.Background())
+	if err != nil || existing.ID != "party" {
+		t.Fatalf("expected existing album, got %+v, %v", existing, err)
+	}
+	if library.CountRequests("POST", "/v1/albums") != 0 || listRequests() != 1 {
+		t.Error("existing album should be returned from index without creating album")
+	}
+	created, err := s.GetOrCreate("New", context.Background())
+	if err != nil || created.ID == "" {
+		t.Fatalf("expected created album, got %+v, %v", created, err)
+	}
+	found, _ := sCopy.FindByTitle("New", nil, context.Background())
+	if len(found) != 1 || found[0].ID != created.ID || listRequests() != 2 {
+		t.Errorf("expected index to be reloaded after create, got %v after %d listings", albumIds(found), listRequests())
+	}
+	_, err = s.Patch(Album{ID: "party", Title: "Birthday"}, []Field{AlbumFieldTitle}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found, _ = s.FindByTitle("Birthday", nil, context.Background())
+	if len(found) != 1 || found[0].ID != "party" || listRequests() != 3 {
+		t.Errorf("expected index to be reloaded after patch, got %v after %d listings", albumIds(found), listRequests())
+	}
+	if found, _ = s.FindByTitle("Party", nil, context.Background()); len(found) != 0 {
+		t.Errorf("old title shouldn't be found, got %v", albumIds(found))
+	}
+}
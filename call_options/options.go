@@ -0,0 +1,137 @@
+package call_options
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Option modifying single service method call. Options apply to every HTTP request made by the method (e.g. to each
+// page fetched by ListAll)
+type CallOption func(settings *Settings)
+
+// Settings of single call built from client defaults and call options
+type Settings struct {
+	// Timeout of each HTTP request. Zero means no timeout other than one set in context
+	Timeout time.Duration
+	// Number of retries of requests that failed with network error, 429 or 5xx status. Only GET requests and calls
+	// marked as idempotent (see WithIdempotent) are retried. Requests with non-rewindable bodies (e.g. uploads) are
+	// never retried
+	Retries int
+	// Call can be safely repeated (e.g. search or batchAddMediaItems). Set by services for idempotent POST/PATCH
+	// endpoints
+	Idempotent bool
+	// Delay before first retry. Doubled for each next retry. Defaults to 1 second
+	RetryDelay time.Duration
+	// Additional request headers
+	Header http.Header
+	// Partial response selector sent as `fields` query parameter
+	Fields string
+	// Filled with metadata of last response
+	ResponseMeta *ResponseMeta
+}
+
+// Metadata of HTTP call. Latency and attempts cover all retries of call
+type ResponseMeta struct {
+	Method string
+	// URL path without query (e.g. "/v1/albums")
+	Path string
+	// Zero when no response was received
+	StatusCode int
+	Header     http.Header
+	// Value of first Google request ID header found in response (see RequestIdHeaders)
+	RequestId string
+	Latency   time.Duration
+	Attempts  int
+}
+
+// Response headers checked for request ID, in order
+var RequestIdHeaders = []string{"X-Google-Request-Id", "X-Goog-Request-Id", "X-Request-Id", "X-Guploader-Uploadid"}
+
+type responseMetaKey struct{}
+
+// Returns context carrying meta. Client puts meta of current call into request context so HTTP middleware
+// (e.g. http.RoundTripper wrapping authenticated client transport) can read it
+func ContextWithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+// Returns meta of call that request belongs to. Method and Path are set before request is sent, remaining fields
+// are filled when response is received
+func ResponseMetaFromContext(ctx context.Context) (*ResponseMeta, bool) {
+	meta, ok := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return meta, ok
+}
+
+// Builds settings from options. Later options override earlier ones
+func NewSettings(opts ...CallOption) Settings {
+	result := Settings{
+		RetryDelay: time.Second,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&result)
+		}
+	}
+	return result
+}
+
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(settings *Settings) {
+		settings.Timeout = timeout
+	}
+}
+
+// Retries failed requests up to specified number of times with exponential backoff starting at delay
+func WithRetries(retries int, delay time.Duration) CallOption {
+	return func(settings *Settings) {
+		settings.Retries = retries
+		if delay > 0 {
+			settings.RetryDelay = delay
+		}
+	}
+}
+
+// Marks call as safe to repeat so it's retried even though it isn't GET request. Don't use for calls creating
+// resources (create, batchCreate, addEnrichment, share, join) - request that timed out after server committed it
+// would be repeated
+func WithIdempotent() CallOption {
+	return func(settings *Settings) {
+		settings.Idempotent = true
+	}
+}
+
+// Disables retries enabled by client defaults
+func WithoutRetries() CallOption {
+	return func(settings *Settings) {
+		settings.Retries = 0
+	}
+}
+
+// Adds request header. Can be used multiple times
+func WithHeader(key string, value string) CallOption {
+	return func(settings *Settings) {
+		if settings.Header == nil {
+			settings.Header = make(http.Header)
+		}
+		settings.Header.Add(key, value)
+	}
+}
+
+// Requests partial response with specified fields (e.g. "mediaItems(id,filename),nextPageToken"). Fields missing
+// in response are left empty in returned models
+//
+// Doc: https://cloud.google.com/apis/docs/system-parameters
+func WithFields(fields string) CallOption {
+	return func(settings *Settings) {
+		settings.Fields = fields
+	}
+}
+
+// Fills meta with metadata of response. When method makes multiple requests metadata of the last one to finish is
+// kept. Meta should be read only after method returns
+func WithResponseMeta(meta *ResponseMeta) CallOption {
+	return func(settings *Settings) {
+		settings.ResponseMeta = meta
+	}
+}
@@ -1,25 +1,75 @@
 package google_photos_api_client
 
 import (
+	"context"
+	"errors"
 	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+	"github.com/duffpl/google-photos-api-client/internal"
 	"github.com/duffpl/google-photos-api-client/media_items"
 	"github.com/duffpl/google-photos-api-client/shared_albums"
 	"github.com/duffpl/google-photos-api-client/uploader"
 	"net/http"
+	"strings"
 )
 
+// Error returned by API. Use errors.As to get it from errors returned by services
+type ApiError = internal.ApiError
+
+// Error of API call carrying request method, path and response metadata. Wraps all errors returned by HTTP client
+type RequestError = internal.RequestError
+
+// Client with all resource services. Has to be created with NewApiClient
 type ApiClient struct {
 	Albums       albums.AlbumsService
 	MediaItems   media_items.MediaItemsService
 	SharedAlbums shared_albums.SharedAlbumsService
+	c            *internal.HttpClient
+}
+
+// Option configuring ApiClient
+type ClientOption = client_options.ClientOption
+
+// Sets call options applied to every call made by client services. Options passed to method override them
+func WithDefaultCallOptions(opts ...call_options.CallOption) ClientOption {
+	return client_options.WithDefaultCallOptions(opts...)
+}
+
+// Structured logger compatible with *slog.Logger
+type Logger = client_options.Logger
+
+type LoggingOptions = client_options.LoggingOptions
+
+// Logs every HTTP request made by client (method, path, status, duration, attempt and payload sizes)
+func WithLogger(logger Logger, options *LoggingOptions) ClientOption {
+	return client_options.WithLogger(logger, options)
+}
+
+// Calls instrumentation hooks (metrics, tracing) for every API call and upload. Use instrumentation.Combine for
+// multiple instrumentations
+func WithInstrumentation(i instrumentation.Instrumentation) ClientOption {
+	return client_options.WithInstrumentation(i)
 }
 
 // Creates new client with all resource services
-func NewApiClient(authenticatedClient *http.Client) ApiClient {
-	httpUploader := uploader.NewHttpMediaUploader(authenticatedClient)
+func NewApiClient(authenticatedClient *http.Client, options ...ClientOption) ApiClient {
+	httpUploader := uploader.NewHttpMediaUploader(authenticatedClient, options...)
 	return ApiClient{
-		Albums:       albums.NewHttpAlbumsService(authenticatedClient),
-		MediaItems:   media_items.NewHttpMediaItemsService(authenticatedClient, httpUploader),
-		SharedAlbums: shared_albums.NewHttpSharedAlbumsService(authenticatedClient),
+		Albums:       albums.NewHttpAlbumsService(authenticatedClient, options...),
+		MediaItems:   media_items.NewHttpMediaItemsService(authenticatedClient, httpUploader, options...),
+		SharedAlbums: shared_albums.NewHttpSharedAlbumsService(authenticatedClient, options...),
+		c:            internal.NewHttpClient(authenticatedClient, options...),
+	}
+}
+
+// Sends raw request to API endpoint that isn't covered by services. Path is relative to API base URL
+// (e.g. "v1/albums/{id}"), query is url.Values or struct with `url` tags, body is sent as JSON when not nil and
+// response is unmarshaled into out when not nil. Errors are decoded the same way as in services
+func (c ApiClient) Do(ctx context.Context, method string, path string, query interface{}, body interface{}, out interface{}, opts ...call_options.CallOption) error {
+	if c.c == nil {
+		return errors.New("api client is not initialized, use NewApiClient")
 	}
+	return c.c.Do(method, strings.TrimPrefix(path, "/"), query, body, out, nil, ctx, opts...)
 }
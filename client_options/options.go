@@ -0,0 +1,70 @@
+package client_options
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+)
+
+// Option configuring HTTP client used by services
+type ClientOption func(config *Config)
+
+// Configuration of HTTP client built from client options
+type Config struct {
+	// Options applied to every call before options passed to the call
+	DefaultCallOptions []call_options.CallOption
+	// Nil disables logging
+	Logger         Logger
+	LoggingOptions LoggingOptions
+	// Nil disables instrumentation
+	Instrumentation instrumentation.Instrumentation
+}
+
+// Structured logger. Method set is a subset of *slog.Logger so it can be used directly
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...interface{})
+	InfoContext(ctx context.Context, msg string, args ...interface{})
+	WarnContext(ctx context.Context, msg string, args ...interface{})
+	ErrorContext(ctx context.Context, msg string, args ...interface{})
+}
+
+type LoggingOptions struct {
+	// Logs request and response bodies and headers at debug level. Authorization and cookie headers, upload, share
+	// and OAuth tokens and base URLs are redacted
+	DumpBodies bool
+}
+
+// Builds config from options. Later options override earlier ones
+func NewConfig(options ...ClientOption) Config {
+	result := Config{}
+	for _, option := range options {
+		option(&result)
+	}
+	return result
+}
+
+// Sets call options applied to every call made by services. Options passed to method override them
+func WithDefaultCallOptions(opts ...call_options.CallOption) ClientOption {
+	return func(config *Config) {
+		config.DefaultCallOptions = opts
+	}
+}
+
+// Logs every HTTP request (method, path, status, duration, attempt and payload sizes)
+func WithLogger(logger Logger, options *LoggingOptions) ClientOption {
+	return func(config *Config) {
+		config.Logger = logger
+		config.LoggingOptions = LoggingOptions{}
+		if options != nil {
+			config.LoggingOptions = *options
+		}
+	}
+}
+
+// Calls instrumentation hooks (metrics, tracing) for every API call and upload. Use instrumentation.Combine for
+// multiple instrumentations
+func WithInstrumentation(i instrumentation.Instrumentation) ClientOption {
+	return func(config *Config) {
+		config.Instrumentation = i
+	}
+}
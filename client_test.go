@@ -0,0 +1,77 @@
+package google_photos_api_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestApiClientDo(t *testing.T) {
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/albums/album:addEnrichment":
+			body := map[string]interface{}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if r.Method != http.MethodPost || body["albumPosition"] == nil || r.URL.Query().Get("alt") != "json" {
+				t.Errorf("unexpected request %s %s %v", r.Method, r.URL, body)
+			}
+			_, _ = w.Write([]byte(`{"enrichmentItem":{"id":"enrichment"}}`))
+		case "/v1/albums/missing":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Invalid album id.","status":"INVALID_ARGUMENT"}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	apiClient := NewApiClient(client)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           interface{}
+		expectedStatus string
+	}{
+		{"success", http.MethodPost, "/v1/albums/album:addEnrichment", map[string]interface{}{"albumPosition": map[string]string{"position": "FIRST_IN_ALBUM"}}, ""},
+		{"path without leading slash", http.MethodPost, "v1/albums/album:addEnrichment", map[string]interface{}{"albumPosition": map[string]string{"position": "FIRST_IN_ALBUM"}}, ""},
+		{"api error", http.MethodGet, "v1/albums/missing", nil, "INVALID_ARGUMENT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := struct {
+				EnrichmentItem struct {
+					ID string `json:"id"`
+				} `json:"enrichmentItem"`
+			}{}
+			query := url.Values{"alt": {"json"}}
+			err := apiClient.Do(context.Background(), tt.method, tt.path, query, tt.body, &out, call_options.WithoutRetries())
+			if tt.expectedStatus == "" {
+				if err != nil || out.EnrichmentItem.ID != "enrichment" {
+					t.Errorf("unexpected result %+v, %v", out, err)
+				}
+				return
+			}
+			apiErr := ApiError{}
+			if !errors.As(err, &apiErr) || apiErr.Status != tt.expectedStatus {
+				t.Errorf("expected %s api error, got %v", tt.expectedStatus, err)
+			}
+			requestErr := RequestError{}
+			if !errors.As(err, &requestErr) || requestErr.Meta.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected request error with status code, got %v", err)
+			}
+		})
+	}
+}
+
+func TestZeroApiClientDo(t *testing.T) {
+	err := ApiClient{}.Do(context.Background(), http.MethodGet, "v1/albums", nil, nil, nil)
+	if err == nil {
+		t.Error("expected error")
+	}
+}
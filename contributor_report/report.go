@@ -0,0 +1,171 @@
+package contributor_report
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Contributions of single user. API doesn't expose time when item was added so creation time of media items is used.
+// API doesn't expose contributor ID either (only display name and profile picture URL) so contributors are
+// identified by display name. Different users with the same display name are reported as one contributor
+type ContributorStats struct {
+	DisplayName       string         `json:"displayName"`
+	MediaItemsCount   int            `json:"mediaItemsCount"`
+	PhotosCount       int            `json:"photosCount"`
+	VideosCount       int            `json:"videosCount"`
+	MimeTypes         map[string]int `json:"mimeTypes"`
+	FirstContribution time.Time      `json:"firstContribution"`
+	LastContribution  time.Time      `json:"lastContribution"`
+}
+
+type Report struct {
+	AlbumId string `json:"albumId"`
+	// Ordered by number of contributed media items
+	Contributors []ContributorStats `json:"contributors"`
+	GeneratedAt  time.Time          `json:"generatedAt"`
+}
+
+var csvHeader = []string{
+	"displayName", "mediaItemsCount", "photosCount", "videosCount", "firstContribution", "lastContribution",
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Writes one row per contributor. Mime types breakdown is available only in JSON format
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return fmt.Errorf("cannot write header: %w", err)
+	}
+	for _, contributor := range r.Contributors {
+		err = writer.Write([]string{
+			contributor.DisplayName,
+			strconv.Itoa(contributor.MediaItemsCount),
+			strconv.Itoa(contributor.PhotosCount),
+			strconv.Itoa(contributor.VideosCount),
+			formatTime(contributor.FirstContribution),
+			formatTime(contributor.LastContribution),
+		})
+		if err != nil {
+			return fmt.Errorf("cannot write row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(r)
+	if err != nil {
+		return fmt.Errorf("cannot encode report: %w", err)
+	}
+	return nil
+}
+
+// Builds contributor reports for shared albums
+type Reporter struct {
+	mediaItems media_items.MediaItemsService
+}
+
+// Builds report for album specified by id. Media items are streamed with SearchAllAsync. Contributor info is only
+// returned for shared albums
+func (r Reporter) Build(albumId string, ctx context.Context) (*Report, error) {
+	// stops listing when report fails before all items are read
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	itemsC, errorsC := r.mediaItems.SearchAllAsync(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, listCtx)
+	contributors := make(map[string]*ContributorStats)
+	for {
+		var item media_items.MediaItem
+		var ok bool
+		select {
+		case item, ok = <-itemsC:
+		case err := <-errorsC:
+			return nil, fmt.Errorf("cannot list album items: %w", err)
+		}
+		if !ok {
+			break
+		}
+		err := addItem(contributors, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	result := &Report{
+		AlbumId:      albumId,
+		Contributors: make([]ContributorStats, 0, len(contributors)),
+		GeneratedAt:  time.Now(),
+	}
+	for _, contributor := range contributors {
+		result.Contributors = append(result.Contributors, *contributor)
+	}
+	sort.Slice(result.Contributors, func(i, j int) bool {
+		a, b := result.Contributors[i], result.Contributors[j]
+		if a.MediaItemsCount != b.MediaItemsCount {
+			return a.MediaItemsCount > b.MediaItemsCount
+		}
+		return a.DisplayName < b.DisplayName
+	})
+	return result, nil
+}
+
+// Contributors are keyed by display name, see ContributorStats
+func addItem(contributors map[string]*ContributorStats, item media_items.MediaItem) error {
+	name := item.ContributorInfo.DisplayName
+	contributor, ok := contributors[name]
+	if !ok {
+		contributor = &ContributorStats{
+			DisplayName: name,
+			MimeTypes:   make(map[string]int),
+		}
+		contributors[name] = contributor
+	}
+	contributor.MediaItemsCount++
+	contributor.MimeTypes[item.MimeType]++
+	if item.MediaMetadata.VideoMetadata != nil {
+		contributor.VideosCount++
+	} else if item.MediaMetadata.PhotoMetadata != nil {
+		contributor.PhotosCount++
+	}
+	if item.MediaMetadata.CreationTime == "" {
+		return nil
+	}
+	creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+	if err != nil {
+		return fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+	}
+	if contributor.FirstContribution.IsZero() || creationTime.Before(contributor.FirstContribution) {
+		contributor.FirstContribution = creationTime
+	}
+	if creationTime.After(contributor.LastContribution) {
+		contributor.LastContribution = creationTime
+	}
+	return nil
+}
+
+func NewReporter(mediaItemsService media_items.MediaItemsService) Reporter {
+	return Reporter{
+		mediaItems: mediaItemsService,
+	}
+}
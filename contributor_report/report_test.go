@@ -0,0 +1,151 @@
+package contributor_report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Streams items until its context is cancelled
+type endlessMediaItemsService struct {
+	media_items.MediaItemsService
+	stopped chan struct{}
+}
+
+func (s endlessMediaItemsService) SearchAllAsync(_ *media_items.SearchOptions, ctx context.Context, _ ...call_options.CallOption) (<-chan media_items.MediaItem, <-chan error) {
+	itemsC := make(chan media_items.MediaItem)
+	go func() {
+		defer close(s.stopped)
+		defer close(itemsC)
+		for {
+			item := media_items.MediaItem{ID: "item"}
+			item.MediaMetadata.CreationTime = "invalid"
+			select {
+			case <-ctx.Done():
+				return
+			case itemsC <- item:
+			}
+		}
+	}()
+	return itemsC, make(chan error)
+}
+
+func TestBuildStopsListingOnError(t *testing.T) {
+	service := endlessMediaItemsService{stopped: make(chan struct{})}
+	_, err := NewReporter(service).Build("album", context.Background())
+	if err == nil {
+		t.Fatal("expected invalid creation time error")
+	}
+	select {
+	case <-service.stopped:
+	case <-time.After(time.Second):
+		t.Error("listing goroutine was not stopped")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	// API-shaped pages of album search
+	pages := map[string]string{
+		"": `{"mediaItems": [
+			{"id": "1", "mimeType": "image/jpeg", "mediaMetadata": {"creationTime": "2024-01-02T10:00:00Z", "photo": {}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/a", "displayName": "Alice"}},
+			{"id": "2", "mimeType": "video/mp4", "mediaMetadata": {"creationTime": "2024-01-01T10:00:00Z", "video": {"fps": 30, "status": "READY"}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/a", "displayName": "Alice"}},
+			{"id": "3", "mimeType": "image/jpeg", "mediaMetadata": {"creationTime": "2024-01-05T10:00:00Z", "photo": {"cameraMake": "Canon"}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/b", "displayName": "Bob"}}
+		], "nextPageToken": "page2"}`,
+		"page2": `{"mediaItems": [
+			{"id": "4", "mimeType": "image/png", "mediaMetadata": {"creationTime": "2024-01-03T10:00:00Z", "photo": {}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/a", "displayName": "Alice"}},
+			{"id": "5", "mimeType": "image/jpeg", "mediaMetadata": {"photo": {}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/c", "displayName": "Carol"}},
+			{"id": "6", "mimeType": "image/jpeg", "mediaMetadata": {"creationTime": "2024-02-01T10:00:00Z", "photo": {}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/d1", "displayName": "Dave"}},
+			{"id": "7", "mimeType": "image/jpeg", "mediaMetadata": {"creationTime": "2024-02-02T10:00:00Z", "photo": {}},
+				"contributorInfo": {"profilePictureBaseUrl": "https://lh3.googleusercontent.com/d2", "displayName": "Dave"}}
+		]}`,
+	}
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			AlbumId   string `json:"albumId"`
+			PageToken string `json:"pageToken"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/mediaItems:search" || body.AlbumId != "album" {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, body)
+		}
+		_, _ = w.Write([]byte(pages[body.PageToken]))
+	}))
+	report, err := NewReporter(media_items.NewHttpMediaItemsService(client, nil)).Build("album", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 10, 0, 0, 0, time.UTC)
+	}
+	expected := []ContributorStats{
+		{"Alice", 3, 2, 1, map[string]int{"image/jpeg": 1, "image/png": 1, "video/mp4": 1}, day(1, 1), day(1, 3)},
+		// users with the same display name are merged
+		{"Dave", 2, 2, 0, map[string]int{"image/jpeg": 2}, day(2, 1), day(2, 2)},
+		{"Bob", 1, 1, 0, map[string]int{"image/jpeg": 1}, day(1, 5), day(1, 5)},
+		{"Carol", 1, 1, 0, map[string]int{"image/jpeg": 1}, time.Time{}, time.Time{}},
+	}
+	if report.AlbumId != "album" || !reflect.DeepEqual(report.Contributors, expected) {
+		t.Errorf("expected contributors %+v, got %+v", expected, report.Contributors)
+	}
+}
+
+func testReport() Report {
+	return Report{
+		AlbumId: "album",
+		Contributors: []ContributorStats{
+			{"Alice, Jr.", 2, 1, 1, map[string]int{"image/jpeg": 1, "video/mp4": 1},
+				time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)},
+			{"Bob", 1, 1, 0, map[string]int{"image/jpeg": 1}, time.Time{}, time.Time{}},
+		},
+		GeneratedAt: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	err := testReport().WriteCSV(buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "displayName,mediaItemsCount,photosCount,videosCount,firstContribution,lastContribution\n" +
+		"\"Alice, Jr.\",2,1,1,2024-01-01T10:00:00Z,2024-01-03T10:00:00Z\n" +
+		"Bob,1,1,0,,\n"
+	if buffer.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buffer.String())
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	err := testReport().WriteJSON(buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, field := range []string{`"albumId": "album"`, `"displayName": "Alice, Jr."`, `"mimeTypes": {`, `"firstContribution": "2024-01-01T10:00:00Z"`} {
+		if !strings.Contains(buffer.String(), field) {
+			t.Errorf("expected %s in %s", field, buffer.String())
+		}
+	}
+	decoded := Report{}
+	err = json.Unmarshal(buffer.Bytes(), &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, testReport()) {
+		t.Errorf("expected %+v after round trip, got %+v", testReport(), decoded)
+	}
+}
@@ -6,4 +6,5 @@ require (
 	github.com/gabriel-vasile/mimetype v1.1.1
 	github.com/google/go-querystring v1.0.0
 	github.com/imdario/mergo v0.3.10
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,71 @@
+package instrumentation
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Hooks called by HTTP client for every API call (including uploads). Call covers all retries of request
+type Instrumentation interface {
+	// Called before call is sent. Returned context is used for request and passed to CallEnd so it can carry span.
+	// Headers in info can be modified (e.g. to inject trace propagation headers)
+	CallStart(ctx context.Context, info CallInfo) context.Context
+	CallEnd(ctx context.Context, info CallInfo, result CallResult)
+}
+
+type CallInfo struct {
+	Method string
+	// Path with resource IDs replaced by {id} (e.g. "/v1/albums/{id}:batchAddMediaItems")
+	Endpoint string
+	Header   http.Header
+}
+
+type CallResult struct {
+	// Zero when no response was received
+	StatusCode int
+	// Empty for successful calls. ApiError.Status for API errors, otherwise NOT_FOUND, CANCELLED,
+	// DEADLINE_EXCEEDED or UNKNOWN
+	ErrorStatus string
+	Err         error
+	Duration    time.Duration
+	Attempts    int
+	// Number of file bytes sent by uploads. Zero for other calls
+	UploadedBytes int64
+}
+
+// Replaces resource IDs (and share tokens) in API path with {id} so it can be used as metric label
+func NormalizeEndpoint(path string) string {
+	parts := strings.Split(path, "/")
+	// "", "v1", collection, id
+	for i := 3; i < len(parts); i++ {
+		verb := ""
+		if colon := strings.Index(parts[i], ":"); colon != -1 {
+			verb = parts[i][colon:]
+		}
+		parts[i] = "{id}" + verb
+	}
+	return strings.Join(parts, "/")
+}
+
+type combined []Instrumentation
+
+func (c combined) CallStart(ctx context.Context, info CallInfo) context.Context {
+	for _, instrumentation := range c {
+		ctx = instrumentation.CallStart(ctx, info)
+	}
+	return ctx
+}
+
+func (c combined) CallEnd(ctx context.Context, info CallInfo, result CallResult) {
+	// reverse order so nested spans are ended first
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].CallEnd(ctx, info, result)
+	}
+}
+
+// Returns instrumentation calling all passed instrumentations (e.g. Prometheus metrics and OpenTelemetry traces)
+func Combine(instrumentations ...Instrumentation) Instrumentation {
+	return combined(instrumentations)
+}
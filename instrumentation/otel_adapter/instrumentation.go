@@ -0,0 +1,125 @@
+package otel_adapter
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/duffpl/google-photos-api-client/instrumentation/otel_adapter"
+
+type Options struct {
+	// Defaults to global tracer provider
+	TracerProvider trace.TracerProvider
+	// Defaults to global meter provider
+	MeterProvider metric.MeterProvider
+	// Used to inject trace context into request headers. Defaults to global propagator
+	Propagator propagation.TextMapPropagator
+}
+
+// OpenTelemetry instrumentation creating client span and recording metrics for every API call
+type Instrumentation struct {
+	tracer        trace.Tracer
+	propagator    propagation.TextMapPropagator
+	requests      metric.Int64Counter
+	errors        metric.Int64Counter
+	latency       metric.Float64Histogram
+	uploadedBytes metric.Int64Counter
+}
+
+func (i Instrumentation) CallStart(ctx context.Context, info instrumentation.CallInfo) context.Context {
+	ctx, _ = i.tracer.Start(ctx, fmt.Sprintf("%s %s", info.Method, info.Endpoint),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", info.Method),
+			attribute.String("url.template", info.Endpoint),
+		),
+	)
+	if info.Header != nil {
+		i.propagator.Inject(ctx, propagation.HeaderCarrier(info.Header))
+	}
+	return ctx
+}
+
+func (i Instrumentation) CallEnd(ctx context.Context, info instrumentation.CallInfo, result instrumentation.CallResult) {
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", info.Method),
+		attribute.String("url.template", info.Endpoint),
+		attribute.Int("http.response.status_code", result.StatusCode),
+	}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attributes...)
+	span.SetAttributes(attribute.Int("http.request.resend_count", result.Attempts-1))
+	if result.Err != nil {
+		span.RecordError(result.Err)
+		span.SetStatus(codes.Error, result.ErrorStatus)
+	}
+	span.End()
+	i.requests.Add(ctx, 1, metric.WithAttributes(attributes...))
+	if result.ErrorStatus != "" {
+		i.errors.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("http.request.method", info.Method),
+			attribute.String("url.template", info.Endpoint),
+			attribute.String("error.type", result.ErrorStatus),
+		))
+	}
+	i.latency.Record(ctx, result.Duration.Seconds(), metric.WithAttributes(
+		attribute.String("http.request.method", info.Method),
+		attribute.String("url.template", info.Endpoint),
+	))
+	if result.UploadedBytes > 0 {
+		i.uploadedBytes.Add(ctx, result.UploadedBytes)
+	}
+}
+
+func New(options *Options) (*Instrumentation, error) {
+	instrumentationOptions := Options{}
+	if options != nil {
+		instrumentationOptions = *options
+	}
+	if instrumentationOptions.TracerProvider == nil {
+		instrumentationOptions.TracerProvider = otel.GetTracerProvider()
+	}
+	if instrumentationOptions.MeterProvider == nil {
+		instrumentationOptions.MeterProvider = otel.GetMeterProvider()
+	}
+	if instrumentationOptions.Propagator == nil {
+		instrumentationOptions.Propagator = otel.GetTextMapPropagator()
+	}
+	meter := instrumentationOptions.MeterProvider.Meter(instrumentationName)
+	result := &Instrumentation{
+		tracer:     instrumentationOptions.TracerProvider.Tracer(instrumentationName),
+		propagator: instrumentationOptions.Propagator,
+	}
+	var err error
+	result.requests, err = meter.Int64Counter("google_photos.api.requests",
+		metric.WithDescription("Number of API calls"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create requests counter: %w", err)
+	}
+	result.errors, err = meter.Int64Counter("google_photos.api.errors",
+		metric.WithDescription("Number of failed API calls by API error status"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create errors counter: %w", err)
+	}
+	result.latency, err = meter.Float64Histogram("google_photos.api.duration",
+		metric.WithDescription("Duration of API calls including retries"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create latency histogram: %w", err)
+	}
+	result.uploadedBytes, err = meter.Int64Counter("google_photos.uploaded_bytes",
+		metric.WithDescription("Number of media bytes uploaded"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create uploaded bytes counter: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package otel_adapter
+
+import (
+	"context"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestInstrumentation(t *testing.T) (*Instrumentation, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	i, err := New(&Options{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		Propagator:     propagation.TraceContext{},
+	})
+	if err != nil {
+		t.Fatalf("cannot create instrumentation: %v", err)
+	}
+	return i, spanRecorder, reader
+}
+
+func TestSpans(t *testing.T) {
+	i, spanRecorder, _ := newTestInstrumentation(t)
+	info := instrumentation.CallInfo{
+		Method:   "GET",
+		Endpoint: "/v1/albums/{id}",
+		Header:   http.Header{},
+	}
+	ctx := i.CallStart(context.Background(), info)
+	if info.Header.Get("traceparent") == "" {
+		t.Error("trace context not injected into request headers")
+	}
+	if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		t.Error("span not stored in context")
+	}
+	i.CallEnd(ctx, info, instrumentation.CallResult{
+		StatusCode:  404,
+		ErrorStatus: "NOT_FOUND",
+		Err:         errors.New("not found"),
+		Duration:    time.Millisecond,
+		Attempts:    2,
+	})
+	spans := spanRecorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 ended span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /v1/albums/{id}" || span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("unexpected span %s of kind %s", span.Name(), span.SpanKind())
+	}
+	if span.Status().Code != codes.Error || span.Status().Description != "NOT_FOUND" {
+		t.Errorf("unexpected span status %+v", span.Status())
+	}
+	attributes := attribute.NewSet(span.Attributes()...)
+	if value, _ := attributes.Value("http.response.status_code"); value.AsInt64() != 404 {
+		t.Errorf("unexpected status code attribute %v", value.Emit())
+	}
+	if value, _ := attributes.Value("http.request.resend_count"); value.AsInt64() != 1 {
+		t.Errorf("unexpected resend count attribute %v", value.Emit())
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	i, _, reader := newTestInstrumentation(t)
+	info := instrumentation.CallInfo{Method: "POST", Endpoint: "/v1/uploads"}
+	ctx := context.Background()
+	i.CallEnd(i.CallStart(ctx, info), info, instrumentation.CallResult{
+		StatusCode:    200,
+		Duration:      time.Second,
+		Attempts:      1,
+		UploadedBytes: 1024,
+	})
+	i.CallEnd(i.CallStart(ctx, info), info, instrumentation.CallResult{
+		StatusCode:  500,
+		ErrorStatus: "INTERNAL",
+		Err:         errors.New("internal"),
+		Duration:    time.Second,
+		Attempts:    1,
+	})
+	data := metricdata.ResourceMetrics{}
+	err := reader.Collect(ctx, &data)
+	if err != nil {
+		t.Fatalf("cannot collect metrics: %v", err)
+	}
+	sums := make(map[string]int64)
+	histogramCounts := make(map[string]uint64)
+	for _, scopeMetrics := range data.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range d.DataPoints {
+					sums[m.Name] += point.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range d.DataPoints {
+					histogramCounts[m.Name] += point.Count
+				}
+			}
+		}
+	}
+	expectedSums := map[string]int64{
+		"google_photos.api.requests":   2,
+		"google_photos.api.errors":     1,
+		"google_photos.uploaded_bytes": 1024,
+	}
+	for name, expected := range expectedSums {
+		if sums[name] != expected {
+			t.Errorf("%s: expected %d, got %d", name, expected, sums[name])
+		}
+	}
+	if histogramCounts["google_photos.api.duration"] != 2 {
+		t.Errorf("expected 2 duration observations, got %d", histogramCounts["google_photos.api.duration"])
+	}
+}
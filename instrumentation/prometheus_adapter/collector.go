@@ -0,0 +1,91 @@
+package prometheus_adapter
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+)
+
+type CollectorOptions struct {
+	// Defaults to "google_photos"
+	Namespace string
+	// Latency histogram buckets in seconds. Defaults to prometheus.DefBuckets
+	Buckets []float64
+}
+
+// Prometheus collector implementing instrumentation hooks. Register it in prometheus registry and pass it to
+// WithInstrumentation client option
+type Collector struct {
+	requests      *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	uploadedBytes prometheus.Counter
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.errors.Describe(ch)
+	c.latency.Describe(ch)
+	c.uploadedBytes.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.errors.Collect(ch)
+	c.latency.Collect(ch)
+	c.uploadedBytes.Collect(ch)
+}
+
+func (c *Collector) CallStart(ctx context.Context, _ instrumentation.CallInfo) context.Context {
+	return ctx
+}
+
+func (c *Collector) CallEnd(_ context.Context, info instrumentation.CallInfo, result instrumentation.CallResult) {
+	c.requests.WithLabelValues(info.Method, info.Endpoint, strconv.Itoa(result.StatusCode)).Inc()
+	if result.ErrorStatus != "" {
+		c.errors.WithLabelValues(info.Method, info.Endpoint, result.ErrorStatus).Inc()
+	}
+	c.latency.WithLabelValues(info.Method, info.Endpoint).Observe(result.Duration.Seconds())
+	if result.UploadedBytes > 0 {
+		c.uploadedBytes.Add(float64(result.UploadedBytes))
+	}
+}
+
+func NewCollector(options *CollectorOptions) *Collector {
+	collectorOptions := CollectorOptions{
+		Namespace: "google_photos",
+		Buckets:   prometheus.DefBuckets,
+	}
+	if options != nil {
+		if options.Namespace != "" {
+			collectorOptions.Namespace = options.Namespace
+		}
+		if len(options.Buckets) > 0 {
+			collectorOptions.Buckets = options.Buckets
+		}
+	}
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: collectorOptions.Namespace,
+			Name:      "api_requests_total",
+			Help:      "Number of API calls by endpoint and HTTP status (0 when no response was received).",
+		}, []string{"method", "endpoint", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: collectorOptions.Namespace,
+			Name:      "api_errors_total",
+			Help:      "Number of failed API calls by endpoint and API error status.",
+		}, []string{"method", "endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: collectorOptions.Namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Duration of API calls including retries.",
+			Buckets:   collectorOptions.Buckets,
+		}, []string{"method", "endpoint"}),
+		uploadedBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: collectorOptions.Namespace,
+			Name:      "uploaded_bytes_total",
+			Help:      "Number of media bytes uploaded.",
+		}),
+	}
+}
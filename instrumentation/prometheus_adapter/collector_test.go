@@ -0,0 +1,68 @@
+package prometheus_adapter
+
+import (
+	"context"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector(nil)
+	ctx := context.Background()
+	listInfo := instrumentation.CallInfo{Method: "GET", Endpoint: "/v1/albums"}
+	c.CallEnd(c.CallStart(ctx, listInfo), listInfo, instrumentation.CallResult{
+		StatusCode: 200,
+		Duration:   10 * time.Millisecond,
+		Attempts:   1,
+	})
+	getInfo := instrumentation.CallInfo{Method: "GET", Endpoint: "/v1/albums/{id}"}
+	c.CallEnd(c.CallStart(ctx, getInfo), getInfo, instrumentation.CallResult{
+		StatusCode:  404,
+		ErrorStatus: "NOT_FOUND",
+		Err:         errors.New("not found"),
+		Duration:    20 * time.Millisecond,
+		Attempts:    1,
+	})
+	uploadInfo := instrumentation.CallInfo{Method: "POST", Endpoint: "/v1/uploads"}
+	c.CallEnd(c.CallStart(ctx, uploadInfo), uploadInfo, instrumentation.CallResult{
+		StatusCode:    200,
+		Duration:      time.Second,
+		Attempts:      1,
+		UploadedBytes: 1024,
+	})
+
+	expected := `
+# HELP google_photos_api_requests_total Number of API calls by endpoint and HTTP status (0 when no response was received).
+# TYPE google_photos_api_requests_total counter
+google_photos_api_requests_total{code="200",endpoint="/v1/albums",method="GET"} 1
+google_photos_api_requests_total{code="200",endpoint="/v1/uploads",method="POST"} 1
+google_photos_api_requests_total{code="404",endpoint="/v1/albums/{id}",method="GET"} 1
+# HELP google_photos_api_errors_total Number of failed API calls by endpoint and API error status.
+# TYPE google_photos_api_errors_total counter
+google_photos_api_errors_total{endpoint="/v1/albums/{id}",method="GET",status="NOT_FOUND"} 1
+# HELP google_photos_uploaded_bytes_total Number of media bytes uploaded.
+# TYPE google_photos_uploaded_bytes_total counter
+google_photos_uploaded_bytes_total 1024
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"google_photos_api_requests_total", "google_photos_api_errors_total", "google_photos_uploaded_bytes_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(c, "google_photos_api_request_duration_seconds"); count != 3 {
+		t.Errorf("expected 3 latency series, got %d", count)
+	}
+}
+
+func TestCollectorNamespace(t *testing.T) {
+	c := NewCollector(&CollectorOptions{Namespace: "custom"})
+	info := instrumentation.CallInfo{Method: "GET", Endpoint: "/v1/albums"}
+	c.CallEnd(context.Background(), info, instrumentation.CallResult{StatusCode: 200, Attempts: 1})
+	if count := testutil.CollectAndCount(c, "custom_api_requests_total"); count != 1 {
+		t.Errorf("expected 1 series in custom namespace, got %d", count)
+	}
+}
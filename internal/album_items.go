@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+)
+
+const mediaItemsSearchPath = "v1/mediaItems:search"
+
+// Minimal media item representation for services that cannot depend on media_items package
+type AlbumItem struct {
+	ID            string `json:"id"`
+	Filename      string `json:"filename"`
+	MediaMetadata struct {
+		CreationTime string `json:"creationTime"`
+	} `json:"mediaMetadata"`
+}
+
+type albumItemsSearchBody struct {
+	AlbumId   string `json:"albumId"`
+	PageSize  int    `json:"pageSize"`
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+type albumItemsSearchResponse struct {
+	MediaItems    []AlbumItem `json:"mediaItems"`
+	NextPageToken string      `json:"nextPageToken"`
+}
+
+// Requests single page of mediaItems.search. Body has to contain page token. Used by media_items package and by
+// ListAlbumItems so both send the same request
+func (c *HttpClient) SearchMediaItems(body interface{}, responseModel interface{}, ctx context.Context, opts ...call_options.CallOption) error {
+	return c.PostJSON(mediaItemsSearchPath, nil, body, responseModel, nil, ctx, Idempotent(opts)...)
+}
+
+// Calls fetchPage with token returned by previous call until there are no more pages. Stops when context is done
+func FetchAllPages(fetchPage func(pageToken string) (nextPageToken string, err error), ctx context.Context) error {
+	pageToken := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		nextPageToken, err := fetchPage(pageToken)
+		if err != nil {
+			return err
+		}
+		if nextPageToken == "" {
+			return nil
+		}
+		pageToken = nextPageToken
+	}
+}
+
+// Fetches all media items in album specified by id. Items are returned in album order. Albums and shared albums
+// services cannot use media_items.SearchAll (media_items depends on albums) so album search is kept here.
+// Fields call option is ignored
+func (c *HttpClient) ListAlbumItems(albumId string, ctx context.Context, opts ...call_options.CallOption) ([]AlbumItem, error) {
+	result := make([]AlbumItem, 0)
+	err := FetchAllPages(func(pageToken string) (string, error) {
+		responseModel := &albumItemsSearchResponse{}
+		err := c.SearchMediaItems(albumItemsSearchBody{
+			AlbumId:   albumId,
+			PageSize:  100,
+			PageToken: pageToken,
+		}, responseModel, ctx, WithoutFields(opts)...)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, responseModel.MediaItems...)
+		return responseModel.NextPageToken, nil
+	}, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot search album items: %w", err)
+	}
+	return result, nil
+}
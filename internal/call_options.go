@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"strings"
+)
+
+// Marks call as idempotent. Caller options are applied after so they can override it
+func Idempotent(opts []call_options.CallOption) []call_options.CallOption {
+	result := make([]call_options.CallOption, 0, len(opts)+1)
+	result = append(result, call_options.WithIdempotent())
+	return append(result, opts...)
+}
+
+// Adds fields to caller's partial response mask (see call_options.WithFields) so pagination keeps working. Empty
+// mask means all fields and is left untouched
+func RequireFields(opts []call_options.CallOption, fields ...string) []call_options.CallOption {
+	result := make([]call_options.CallOption, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, func(settings *call_options.Settings) {
+		settings.Fields = addFields(settings.Fields, fields)
+	})
+}
+
+// Drops caller's partial response mask. Used by helpers that need complete resources from internal listings
+func WithoutFields(opts []call_options.CallOption) []call_options.CallOption {
+	result := make([]call_options.CallOption, 0, len(opts)+1)
+	result = append(result, opts...)
+	return append(result, func(settings *call_options.Settings) {
+		settings.Fields = ""
+	})
+}
+
+func addFields(mask string, fields []string) string {
+	if mask == "" {
+		return ""
+	}
+	present := make(map[string]bool)
+	for _, field := range topLevelFields(mask) {
+		present[field] = true
+	}
+	for _, field := range fields {
+		if !present[field] {
+			mask += "," + field
+			present[field] = true
+		}
+	}
+	return mask
+}
+
+// Returns names of top level fields of mask, e.g. "albums(id,title),nextPageToken" gives [albums nextPageToken]
+func topLevelFields(mask string) []string {
+	result := make([]string, 0)
+	depth := 0
+	start := 0
+	appendField := func(end int) {
+		field := strings.TrimSpace(mask[start:end])
+		if i := strings.IndexAny(field, "(/"); i >= 0 {
+			field = field[:i]
+		}
+		if field != "" {
+			result = append(result, field)
+		}
+	}
+	for i, r := range mask {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				appendField(i)
+				start = i + 1
+			}
+		}
+	}
+	appendField(len(mask))
+	return result
+}
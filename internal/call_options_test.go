@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"testing"
+)
+
+func TestRequireFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     string
+		expected string
+	}{
+		{"empty mask", "", ""},
+		{"missing field", "albums(id,title)", "albums(id,title),nextPageToken"},
+		{"present field", "albums(id),nextPageToken", "albums(id),nextPageToken"},
+		{"nested field with the same name", "albums(nextPageToken)", "albums(nextPageToken),nextPageToken"},
+		{"sub-selector", "albums/id", "albums/id,nextPageToken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []call_options.CallOption{call_options.WithFields(tt.mask)}
+			settings := call_options.NewSettings(RequireFields(opts, "nextPageToken")...)
+			if settings.Fields != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, settings.Fields)
+			}
+		})
+	}
+}
+
+func TestWithoutFields(t *testing.T) {
+	opts := []call_options.CallOption{call_options.WithFields("albums(id)")}
+	settings := call_options.NewSettings(WithoutFields(opts)...)
+	if settings.Fields != "" {
+		t.Errorf("expected empty mask, got %q", settings.Fields)
+	}
+}
@@ -0,0 +1,25 @@
+package internal
+
+// Splits ids into chunks of at most size items. Returns no chunks for empty input
+func ChunkStrings(ids []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for start := 0; start < len(ids); start += size {
+		end := Min(start+size, len(ids))
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
+
+// Returns ids without repeated entries, keeping first occurrence of each id
+func UniqueStrings(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
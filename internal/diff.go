@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Returned by PatchDiff when values don't differ. Empty update mask would make Patch update all fields
+var ErrNoChanges = errors.New("no fields changed")
+
+// Returns JSON names of fields that differ between two values of the same struct type
+func DiffFields(old interface{}, new interface{}) []string {
+	oldValue := reflect.Indirect(reflect.ValueOf(old))
+	newValue := reflect.Indirect(reflect.ValueOf(new))
+	result := make([]string, 0)
+	for i := 0; i < oldValue.NumField(); i++ {
+		field := oldValue.Type().Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		result = append(result, name)
+	}
+	return result
+}
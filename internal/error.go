@@ -3,6 +3,7 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
 	"net/http"
 )
 
@@ -27,6 +28,35 @@ type APIStatus struct {
 	Details []interface{} `json:"details"`
 }
 
+// Error of API call with metadata of request. Wraps ApiError for API errors
+type RequestError struct {
+	Meta call_options.ResponseMeta
+	Err  error
+}
+
+// Share tokens in path are redacted so error can be logged safely. Meta keeps original path
+func (e RequestError) Error() string {
+	path := redactPath(e.Meta.Path)
+	if e.Meta.RequestId != "" {
+		return fmt.Sprintf("%s %s (request id %s): %v", e.Meta.Method, path, e.Meta.RequestId, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Meta.Method, path, e.Err)
+}
+
+func (e RequestError) Unwrap() error {
+	return e.Err
+}
+
+// Returns true for 404 responses and API errors with NOT_FOUND status
+func IsNotFoundError(err error) bool {
+	requestErr := RequestError{}
+	if errors.As(err, &requestErr) && requestErr.Meta.StatusCode == http.StatusNotFound {
+		return true
+	}
+	apiErr := ApiError{}
+	return errors.As(err, &apiErr) && apiErr.Status == "NOT_FOUND"
+}
+
 func GetErrorFromResponse(res *http.Response) error {
 	if res.StatusCode < 400 {
 		return nil
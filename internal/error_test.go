@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"strings"
+	"testing"
+)
+
+func TestRequestErrorRedactsShareToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		meta     call_options.ResponseMeta
+		expected string
+	}{
+		{
+			"shared album",
+			call_options.ResponseMeta{Method: "GET", Path: "/v1/sharedAlbums/secret-token"},
+			"GET /v1/sharedAlbums/REDACTED: failed",
+		},
+		{
+			"with request id",
+			call_options.ResponseMeta{Method: "GET", Path: "/v1/sharedAlbums/secret-token", RequestId: "req"},
+			"GET /v1/sharedAlbums/REDACTED (request id req): failed",
+		},
+		{
+			"join",
+			call_options.ResponseMeta{Method: "POST", Path: "/v1/sharedAlbums:join"},
+			"POST /v1/sharedAlbums:join: failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RequestError{Meta: tt.meta, Err: errors.New("failed")}
+			if err.Error() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, err.Error())
+			}
+			if strings.Contains(err.Error(), "secret-token") {
+				t.Error("share token leaked in error message")
+			}
+		})
+	}
+}
+
+func TestIsNotFoundError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"404 response", RequestError{Meta: call_options.ResponseMeta{StatusCode: 404}, Err: errors.New("url not found")}, true},
+		{"not found status", RequestError{Meta: call_options.ResponseMeta{StatusCode: 400}, Err: ApiError{Code: 400, Status: "NOT_FOUND"}}, true},
+		{"invalid argument", RequestError{Meta: call_options.ResponseMeta{StatusCode: 400}, Err: ApiError{Code: 400, Status: "INVALID_ARGUMENT"}}, false},
+		{"network error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFoundError(fmt.Errorf("wrapped: %w", tt.err)); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -5,89 +5,272 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
+	"github.com/duffpl/google-photos-api-client/instrumentation"
 	"github.com/google/go-querystring/query"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// Guards writes to meta passed with WithResponseMeta. Concurrent workers of batch methods share the same settings
+var responseMetaMu sync.Mutex
+
 type HttpClient struct {
-	c *http.Client
+	c               *http.Client
+	defaults        []call_options.CallOption
+	logger          Logger
+	loggingOptions  LoggingOptions
+	instrumentation instrumentation.Instrumentation
 }
 
-func NewHttpClient(c *http.Client) *HttpClient {
+func NewHttpClient(c *http.Client, options ...client_options.ClientOption) *HttpClient {
+	config := client_options.NewConfig(options...)
 	return &HttpClient{
-		c: c,
+		c:               c,
+		defaults:        config.DefaultCallOptions,
+		logger:          config.Logger,
+		loggingOptions:  config.LoggingOptions,
+		instrumentation: config.Instrumentation,
 	}
 }
 
-func (c *HttpClient) FetchWithGet(path string, queryValues interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context) error {
-	req, err := prepareGetRequest(path, queryValues, ctx)
+func (c *HttpClient) settings(opts []call_options.CallOption) call_options.Settings {
+	allOpts := make([]call_options.CallOption, 0, len(c.defaults)+len(opts))
+	allOpts = append(allOpts, c.defaults...)
+	allOpts = append(allOpts, opts...)
+	return call_options.NewSettings(allOpts...)
+}
+
+func (c *HttpClient) FetchWithGet(path string, queryValues interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts ...call_options.CallOption) error {
+	settings := c.settings(opts)
+	req, err := prepareRequest(http.MethodGet, path, queryValues, settings, ctx)
 	if err != nil {
 		return fmt.Errorf("cannot prepare request: %w", err)
 	}
 	if reqCb != nil {
 		reqCb(req)
 	}
-	return c.fetchRequest(req, responseModel)
+	return c.fetchRequest(req, responseModel, nil, settings)
 }
 
-func (c *HttpClient) PostJSON(path string, queryValues interface{}, body interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context) error {
-	return c.doJSONRequest(path, queryValues, body, http.MethodPost, responseModel, reqCb, ctx)
+func (c *HttpClient) PostJSON(path string, queryValues interface{}, body interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts ...call_options.CallOption) error {
+	return c.doJSONRequest(path, queryValues, body, http.MethodPost, responseModel, reqCb, ctx, opts)
 }
 
-func (c *HttpClient) PatchJSON(path string, queryValues interface{}, body interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context) error {
-	return c.doJSONRequest(path, queryValues, body, http.MethodPatch, responseModel, reqCb, ctx)
+func (c *HttpClient) PatchJSON(path string, queryValues interface{}, body interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts ...call_options.CallOption) error {
+	return c.doJSONRequest(path, queryValues, body, http.MethodPatch, responseModel, reqCb, ctx, opts)
 }
 
-func (c *HttpClient) PostFile(path string, queryValues interface{}, file io.Reader, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context) error {
-	req, err := prepareFilePostRequest(path, queryValues, file, ctx)
+// Sends request with any method. Body is sent as JSON when not nil and response is unmarshaled when responseModel
+// is not nil
+func (c *HttpClient) Do(method string, path string, queryValues interface{}, body interface{}, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts ...call_options.CallOption) error {
+	if body != nil {
+		return c.doJSONRequest(path, queryValues, body, method, responseModel, reqCb, ctx, opts)
+	}
+	settings := c.settings(opts)
+	req, err := prepareRequest(method, path, queryValues, settings, ctx)
 	if err != nil {
 		return fmt.Errorf("cannot prepare request: %w", err)
 	}
 	if reqCb != nil {
 		reqCb(req)
 	}
-	return c.fetchRequest(req, responseModel)
+	return c.fetchRequest(req, responseModel, nil, settings)
 }
 
-func (c *HttpClient) doJSONRequest(path string, queryValues interface{}, body interface{}, method string, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context) error {
-	req, err := prepareJsonRequest(path, queryValues, body, method, ctx)
+// Posts file contents. File reader cannot be rewound so upload is never retried
+func (c *HttpClient) PostFile(path string, queryValues interface{}, file io.Reader, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts ...call_options.CallOption) error {
+	settings := c.settings(opts)
+	uploaded := &countingReader{
+		r: file,
+	}
+	req, err := prepareFilePostRequest(path, queryValues, uploaded, settings, ctx)
 	if err != nil {
 		return fmt.Errorf("cannot prepare request: %w", err)
 	}
 	if reqCb != nil {
 		reqCb(req)
 	}
-	return c.fetchRequest(req, responseModel)
+	return c.fetchRequest(req, responseModel, uploaded, settings)
+}
+
+func (c *HttpClient) doJSONRequest(path string, queryValues interface{}, body interface{}, method string, responseModel interface{}, reqCb func(req *http.Request), ctx context.Context, opts []call_options.CallOption) error {
+	settings := c.settings(opts)
+	req, err := prepareJsonRequest(path, queryValues, body, method, settings, ctx)
+	if err != nil {
+		return fmt.Errorf("cannot prepare request: %w", err)
+	}
+	if reqCb != nil {
+		reqCb(req)
+	}
+	return c.fetchRequest(req, responseModel, nil, settings)
+}
+
+// Sends request with retries. Uploaded reader is set for file uploads to count sent bytes
+func (c *HttpClient) fetchRequest(req *http.Request, responseModel interface{}, uploaded *countingReader, settings call_options.Settings) error {
+	for key, values := range settings.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	meta := &call_options.ResponseMeta{
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+	ctx := req.Context()
+	callInfo := instrumentation.CallInfo{
+		Method:   req.Method,
+		Endpoint: instrumentation.NormalizeEndpoint(req.URL.Path),
+		Header:   req.Header,
+	}
+	if c.instrumentation != nil {
+		ctx = c.instrumentation.CallStart(ctx, callInfo)
+	}
+	start := time.Now()
+	err := c.fetchWithRetries(req.WithContext(call_options.ContextWithResponseMeta(ctx, meta)), responseModel, meta, settings)
+	meta.Latency = time.Since(start)
+	if settings.ResponseMeta != nil {
+		responseMetaMu.Lock()
+		*settings.ResponseMeta = *meta
+		responseMetaMu.Unlock()
+	}
+	if c.instrumentation != nil {
+		c.instrumentation.CallEnd(ctx, callInfo, instrumentation.CallResult{
+			StatusCode:    meta.StatusCode,
+			ErrorStatus:   errorStatus(err, meta.StatusCode),
+			Err:           err,
+			Duration:      meta.Latency,
+			Attempts:      meta.Attempts,
+			UploadedBytes: uploaded.count(),
+		})
+	}
+	if err != nil {
+		return RequestError{
+			Meta: *meta,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func (c *HttpClient) fetchWithRetries(req *http.Request, responseModel interface{}, meta *call_options.ResponseMeta, settings call_options.Settings) error {
+	// retrying non-idempotent request after timeout could e.g. create duplicated album
+	isIdempotent := req.Method == http.MethodGet || settings.Idempotent
+	canRetry := isIdempotent && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		meta.Attempts = attempt + 1
+		attemptStart := time.Now()
+		result := c.fetchAttempt(attemptReq, responseModel, meta, settings)
+		willRetry := result.err != nil && result.retry && canRetry && attempt < settings.Retries
+		c.logAttempt(req, meta, result, time.Since(attemptStart), willRetry)
+		if !willRetry {
+			return result.err
+		}
+		delay := settings.RetryDelay << uint(attempt)
+		if result.retryAfter > delay {
+			delay = result.retryAfter
+		}
+		select {
+		case <-req.Context().Done():
+			return result.err
+		case <-time.After(delay):
+		}
+		attemptReq = req.Clone(req.Context())
+		if req.GetBody != nil {
+			var err error
+			attemptReq.Body, err = req.GetBody()
+			if err != nil {
+				return fmt.Errorf("cannot rewind request body: %w", err)
+			}
+		}
+	}
+}
+
+type attemptResult struct {
+	// Whether failed request can be retried
+	retry bool
+	// Delay requested by server
+	retryAfter   time.Duration
+	responseBody []byte
+	err          error
 }
 
-func (c *HttpClient) fetchRequest(req *http.Request, responseModel interface{}) error {
+// Sends request once
+func (c *HttpClient) fetchAttempt(req *http.Request, responseModel interface{}, meta *call_options.ResponseMeta, settings call_options.Settings) attemptResult {
+	parentCtx := req.Context()
+	if settings.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(parentCtx, settings.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	meta.StatusCode = 0
+	meta.Header = nil
+	meta.RequestId = ""
 	res, err := c.c.Do(req)
 	if err != nil {
-		return fmt.Errorf("cannot fetch response: %w", err)
+		return attemptResult{
+			retry: parentCtx.Err() == nil,
+			err:   fmt.Errorf("cannot fetch response: %w", err),
+		}
+	}
+	defer res.Body.Close()
+	meta.StatusCode = res.StatusCode
+	meta.Header = res.Header
+	for _, header := range call_options.RequestIdHeaders {
+		if requestId := res.Header.Get(header); requestId != "" {
+			meta.RequestId = requestId
+			break
+		}
+	}
+	result := attemptResult{}
+	// response is buffered only for logging
+	if c.logger != nil {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return attemptResult{
+				retry: parentCtx.Err() == nil,
+				err:   fmt.Errorf("cannot read response: %w", err),
+			}
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		result.responseBody = body
 	}
 	err = GetErrorFromResponse(res)
 	if err != nil {
-		return fmt.Errorf("invalid response: %w", err)
+		retryAfter, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		result.retry = res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		result.retryAfter = time.Duration(retryAfter) * time.Second
+		result.err = fmt.Errorf("invalid response: %w", err)
+		return result
+	}
+	// endpoints like batchAddMediaItems return empty object that callers don't need
+	if responseModel == nil {
+		return result
 	}
 	err = UnmarshalResponse(res, responseModel)
 	if err != nil {
-		return fmt.Errorf("cannot unmarshal response: %w", err)
+		result.err = fmt.Errorf("cannot unmarshal response: %w", err)
 	}
-	return nil
+	return result
 }
 
-func prepareFilePostRequest(path string, queryValues interface{}, file io.Reader, ctx context.Context) (*http.Request, error) {
-	reqUrl, err := prepareRequestURL(path, queryValues)
+func prepareFilePostRequest(path string, queryValues interface{}, file io.Reader, settings call_options.Settings, ctx context.Context) (*http.Request, error) {
+	reqUrl, err := prepareRequestURL(path, queryValues, settings)
 	if err != nil {
 		return nil, fmt.Errorf("cannot prepare request url: %w", err)
 	}
 	return http.NewRequestWithContext(ctx, http.MethodPost, reqUrl.String(), file)
 
 }
-func prepareJsonRequest(path string, queryValues interface{}, body interface{}, method string, ctx context.Context) (*http.Request, error) {
-	reqUrl, err := prepareRequestURL(path, queryValues)
+func prepareJsonRequest(path string, queryValues interface{}, body interface{}, method string, settings call_options.Settings, ctx context.Context) (*http.Request, error) {
+	reqUrl, err := prepareRequestURL(path, queryValues, settings)
 	if err != nil {
 		return nil, fmt.Errorf("cannot prepare request url: %w", err)
 	}
@@ -99,7 +282,7 @@ func prepareJsonRequest(path string, queryValues interface{}, body interface{},
 	return http.NewRequestWithContext(ctx, method, reqUrl.String(), bodyReader)
 }
 
-func prepareRequestURL(path string, queryValues interface{}) (*url.URL, error) {
+func prepareRequestURL(path string, queryValues interface{}, settings call_options.Settings) (*url.URL, error) {
 	var err error
 	qValues, ok := queryValues.(url.Values)
 	if !ok {
@@ -108,6 +291,15 @@ func prepareRequestURL(path string, queryValues interface{}) (*url.URL, error) {
 			return nil, fmt.Errorf("cannot get query values: %w", err)
 		}
 	}
+	if settings.Fields != "" {
+		// copy to avoid modifying values passed by caller
+		withFields := url.Values{}
+		for key, values := range qValues {
+			withFields[key] = values
+		}
+		withFields.Set("fields", settings.Fields)
+		qValues = withFields
+	}
 	reqUrl := url.URL{
 		Scheme:   "https",
 		Host:     "photoslibrary.googleapis.com",
@@ -117,10 +309,10 @@ func prepareRequestURL(path string, queryValues interface{}) (*url.URL, error) {
 	return &reqUrl, nil
 }
 
-func prepareGetRequest(path string, queryValues interface{}, ctx context.Context) (*http.Request, error) {
-	reqUrl, err := prepareRequestURL(path, queryValues)
+func prepareRequest(method string, path string, queryValues interface{}, settings call_options.Settings, ctx context.Context) (*http.Request, error) {
+	reqUrl, err := prepareRequestURL(path, queryValues, settings)
 	if err != nil {
 		return nil, fmt.Errorf("cannot prepare request url: %w", err)
 	}
-	return http.NewRequestWithContext(ctx, http.MethodGet, reqUrl.String(), nil)
+	return http.NewRequestWithContext(ctx, method, reqUrl.String(), nil)
 }
@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+type stubTransport struct {
+	statusCode int
+	body       string
+	bodies     []*trackedBody
+}
+
+func (t *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := &trackedBody{
+		Reader: strings.NewReader(t.body),
+	}
+	t.bodies = append(t.bodies, body)
+	return &http.Response{
+		StatusCode: t.statusCode,
+		Header:     http.Header{},
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+func TestFetchRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		responseModel interface{}
+		wantErr       bool
+	}{
+		{
+			name:          "nil response model",
+			statusCode:    http.StatusOK,
+			body:          "{}",
+			responseModel: nil,
+		},
+		{
+			name:          "nil response model with empty body",
+			statusCode:    http.StatusOK,
+			body:          "",
+			responseModel: nil,
+		},
+		{
+			name:          "response model",
+			statusCode:    http.StatusOK,
+			body:          `{"id":"1"}`,
+			responseModel: &struct{ ID string }{},
+		},
+		{
+			name:          "api error",
+			statusCode:    http.StatusBadRequest,
+			body:          `{"error":{"code":400,"message":"bad","status":"INVALID_ARGUMENT"}}`,
+			responseModel: nil,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &stubTransport{
+				statusCode: tt.statusCode,
+				body:       tt.body,
+			}
+			c := NewHttpClient(&http.Client{Transport: transport})
+			err := c.PostJSON("v1/albums/1:batchAddMediaItems", nil, struct{}{}, tt.responseModel, nil, context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PostJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(transport.bodies) != 1 || !transport.bodies[0].closed {
+				t.Errorf("response body was not closed")
+			}
+		})
+	}
+}
+
+// Fails with 503 until configured number of failures is reached
+type flakyTransport struct {
+	failures int
+	requests int
+}
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	if req.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, req.Body)
+	}
+	if t.requests <= t.failures {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"error":{"code":503,"message":"unavailable","status":"UNAVAILABLE"}}`)),
+			Request:    req,
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestRetries(t *testing.T) {
+	retries := call_options.WithRetries(2, time.Millisecond)
+	tests := []struct {
+		name         string
+		method       string
+		opts         []call_options.CallOption
+		wantRequests int
+		wantErr      bool
+	}{
+		{
+			name:         "get is retried",
+			method:       http.MethodGet,
+			opts:         []call_options.CallOption{retries},
+			wantRequests: 2,
+		},
+		{
+			name:         "post is not retried",
+			method:       http.MethodPost,
+			opts:         []call_options.CallOption{retries},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "idempotent post is retried",
+			method:       http.MethodPost,
+			opts:         Idempotent([]call_options.CallOption{retries}),
+			wantRequests: 2,
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodGet,
+			opts:         []call_options.CallOption{retries, call_options.WithoutRetries()},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &flakyTransport{
+				failures: 1,
+			}
+			c := NewHttpClient(&http.Client{Transport: transport})
+			var body interface{}
+			if tt.method != http.MethodGet {
+				body = struct{}{}
+			}
+			err := c.Do(tt.method, "v1/albums", nil, body, nil, nil, context.Background(), tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if transport.requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", transport.requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Counts bytes read from uploaded file
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) count() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.n
+}
+
+func errorStatus(err error, statusCode int) string {
+	if err == nil {
+		return ""
+	}
+	apiErr := ApiError{}
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Status != "":
+		return apiErr.Status
+	case statusCode == http.StatusNotFound:
+		return "NOT_FOUND"
+	case errors.Is(err, context.Canceled):
+		return "CANCELLED"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "DEADLINE_EXCEEDED"
+	}
+	return "UNKNOWN"
+}
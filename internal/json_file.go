@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Reads JSON file into dst. Missing file is not an error - dst is left untouched
+func ReadJSONFile(path string, dst interface{}) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+	err = json.Unmarshal(b, dst)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal file: %w", err)
+	}
+	return nil
+}
+
+// Writes value as JSON to temporary file and renames it so existing file is never left half written
+func WriteJSONFile(path string, value interface{}) error {
+	b, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal value: %w", err)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file: %w", err)
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("cannot write temporary file: %w", err)
+	}
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("cannot replace file: %w", err)
+	}
+	return nil
+}
+
+// Value persisted in single JSON file. File is read on every call so changes made by other instances (or processes
+// run one after another) are visible. Calls of the same store and its copies are serialized
+type JSONFileStore struct {
+	path string
+	m    *sync.Mutex
+}
+
+// Reads file into dst. Missing file leaves dst untouched
+func (s JSONFileStore) Load(dst interface{}) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return ReadJSONFile(s.path, dst)
+}
+
+// Reads file into dst, calls update that changes dst and writes dst back to file
+func (s JSONFileStore) Update(dst interface{}, update func()) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	err := ReadJSONFile(s.path, dst)
+	if err != nil {
+		return err
+	}
+	update()
+	return WriteJSONFile(s.path, dst)
+}
+
+func NewJSONFileStore(path string) JSONFileStore {
+	return JSONFileStore{
+		path: path,
+		m:    &sync.Mutex{},
+	}
+}
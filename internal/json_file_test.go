@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestJSONFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store := NewJSONFileStore(path)
+	missing := map[string]int{"untouched": 1}
+	err := store.Load(&missing)
+	if err != nil || missing["untouched"] != 1 {
+		t.Fatalf("expected missing file to leave value untouched, got %v, %v", missing, err)
+	}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			counters := make(map[string]int)
+			err := store.Update(&counters, func() {
+				counters[strconv.Itoa(i)] = i
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	loaded := make(map[string]int)
+	err = NewJSONFileStore(path).Load(&loaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded) != 20 {
+		t.Errorf("expected all concurrent updates to be kept, got %v", loaded)
+	}
+}
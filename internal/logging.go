@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Logger = client_options.Logger
+
+type LoggingOptions = client_options.LoggingOptions
+
+const redacted = "REDACTED"
+
+var (
+	// share token is the only secret passed in path
+	sharedAlbumPathRegexp = regexp.MustCompile(`(/sharedAlbums/)[^/:]+`)
+	redactedHeaders       = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+)
+
+func redactPath(path string) string {
+	return sharedAlbumPathRegexp.ReplaceAllString(path, "${1}"+redacted)
+}
+
+// Upload, share and OAuth tokens (access_token, refresh_token, id_token). Page tokens are kept
+func isSensitiveField(key string) bool {
+	lowerKey := strings.ToLower(key)
+	return strings.HasSuffix(lowerKey, "token") && !strings.HasSuffix(lowerKey, "pagetoken") ||
+		lowerKey == "shareableurl" ||
+		strings.HasSuffix(lowerKey, "baseurl")
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range v {
+			if isSensitiveField(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(fieldValue)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+	return value
+}
+
+// Returns body with sensitive JSON fields redacted. Non-JSON bodies (uploaded files, upload tokens) are not dumped
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value interface{}
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return fmt.Sprintf("[non-JSON body, %d bytes]", len(body))
+	}
+	redactedBody, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return fmt.Sprintf("[body, %d bytes]", len(body))
+	}
+	return string(redactedBody)
+}
+
+// Header keys are compared case-insensitively since headers set directly in map are not canonicalized
+func redactHeader(header http.Header) http.Header {
+	result := header.Clone()
+	for key := range result {
+		for _, redactedKey := range redactedHeaders {
+			if strings.EqualFold(key, redactedKey) {
+				result[key] = []string{redacted}
+			}
+		}
+	}
+	return result
+}
+
+func (c *HttpClient) logAttempt(req *http.Request, meta *call_options.ResponseMeta, result attemptResult, duration time.Duration, willRetry bool) {
+	if c.logger == nil {
+		return
+	}
+	ctx := req.Context()
+	path := redactPath(meta.Path)
+	args := []interface{}{
+		"method", meta.Method,
+		"path", path,
+		"status", meta.StatusCode,
+		"duration", duration,
+		"attempt", meta.Attempts,
+		"request_size", req.ContentLength,
+		"response_size", len(result.responseBody),
+	}
+	if meta.RequestId != "" {
+		args = append(args, "request_id", meta.RequestId)
+	}
+	switch {
+	case result.err == nil:
+		c.logger.InfoContext(ctx, "photos api request", args...)
+	case willRetry:
+		c.logger.WarnContext(ctx, "photos api request failed, retrying", append(args, "error", result.err.Error())...)
+	default:
+		c.logger.ErrorContext(ctx, "photos api request failed", append(args, "error", result.err.Error())...)
+	}
+	if !c.loggingOptions.DumpBodies {
+		return
+	}
+	requestBody := ""
+	// bodies that cannot be re-read (uploaded files) are not dumped
+	if req.GetBody != nil {
+		if bodyReader, err := req.GetBody(); err == nil {
+			body, _ := ioutil.ReadAll(bodyReader)
+			requestBody = redactBody(body)
+		}
+	}
+	c.logger.DebugContext(ctx, "photos api request dump",
+		"method", meta.Method,
+		"path", path,
+		"attempt", meta.Attempts,
+		"request_header", redactHeader(req.Header),
+		"request_body", requestBody,
+		"response_header", redactHeader(meta.Header),
+		"response_body", redactBody(result.responseBody),
+	)
+}
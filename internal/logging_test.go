@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Logger keeping every message with its arguments formatted as single line
+type recordingLogger struct {
+	m     sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) log(level string, msg string, args []interface{}) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(append([]interface{}{level, msg}, args...)...))
+}
+
+func (l *recordingLogger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("DEBUG", msg, args)
+}
+
+func (l *recordingLogger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("INFO", msg, args)
+}
+
+func (l *recordingLogger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("WARN", msg, args)
+}
+
+func (l *recordingLogger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log("ERROR", msg, args)
+}
+
+func (l *recordingLogger) output() string {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+const secret = "s3cr3t"
+
+// Transport answering every request with response containing secrets
+type secretsTransport struct {
+	statusCode int
+}
+
+func (t secretsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := `{"albums":[{"id":"a","coverPhotoBaseUrl":"https://lh3/` + secret + `","shareInfo":{"shareToken":"` + secret +
+		`","shareableUrl":"https://photos.app.goo.gl/` + secret + `"}}],"access_token":"` + secret +
+		`","refresh_token":"` + secret + `","nextPageToken":"page2"}`
+	return &http.Response{
+		StatusCode: t.statusCode,
+		Header:     http.Header{"Set-Cookie": {"session=" + secret}, "X-Goog-Request-Id": {"request"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestLoggingRedactsSecrets(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		dump       bool
+	}{
+		{"success", 200, false},
+		{"success with dump", 200, true},
+		{"failure with dump", 400, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			c := NewHttpClient(&http.Client{Transport: secretsTransport{statusCode: tt.statusCode}},
+				client_options.WithLogger(logger, &LoggingOptions{DumpBodies: tt.dump}))
+			body := map[string]string{
+				"shareToken":    secret,
+				"uploadToken":   secret,
+				"access_token":  secret,
+				"refresh_token": secret,
+				"pageToken":     "page1",
+			}
+			setHeaders := func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer "+secret)
+				req.Header["cookie"] = []string{"session=" + secret}
+			}
+			_ = c.Do(http.MethodPost, "v1/sharedAlbums/"+secret, nil, body, &map[string]interface{}{}, setHeaders, context.Background())
+			output := logger.output()
+			if output == "" {
+				t.Fatal("nothing was logged")
+			}
+			if strings.Contains(output, secret) {
+				t.Errorf("secret leaked to log:\n%s", output)
+			}
+			if tt.dump && (!strings.Contains(output, "page1") || !strings.Contains(output, "page2")) {
+				t.Errorf("page tokens should be kept in dump:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"non-JSON", "binary " + secret, "[non-JSON body, 13 bytes]"},
+		{"nested", `{"album":{"shareInfo":{"shareToken":"` + secret + `"}}}`, `{"album":{"shareInfo":{"shareToken":"REDACTED"}}}`},
+		{"array", `[{"baseUrl":"` + secret + `"},{"productUrl":"url"}]`, `[{"baseUrl":"REDACTED"},{"productUrl":"url"}]`},
+		{"oauth", `{"access_token":"` + secret + `","id_token":"` + secret + `","expires_in":3600}`, `{"access_token":"REDACTED","expires_in":3600,"id_token":"REDACTED"}`},
+		{"page token", `{"pageToken":"p","nextPageToken":"n"}`, `{"nextPageToken":"n","pageToken":"p"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody([]byte(tt.body)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{
+		"Authorization":       {"Bearer " + secret, "Bearer other"},
+		"proxy-authorization": {secret},
+		"Content-Type":        {"application/json"},
+	}
+	redactedHeader := redactHeader(header)
+	if strings.Contains(fmt.Sprint(redactedHeader), secret) {
+		t.Errorf("secret leaked: %v", redactedHeader)
+	}
+	if redactedHeader.Get("Content-Type") != "application/json" {
+		t.Error("other headers should be kept")
+	}
+	if header.Get("Authorization") != "Bearer "+secret {
+		t.Error("original header shouldn't be changed")
+	}
+}
+
+func TestResponseBufferedOnlyForLogging(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      []client_options.ClientOption
+		expectBuffer bool
+	}{
+		{"without logger", nil, false},
+		{"with logger", []client_options.ClientOption{client_options.WithLogger(&recordingLogger{}, nil)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHttpClient(&http.Client{Transport: &stubTransport{statusCode: 200, body: `{"id":"a"}`}}, tt.options...)
+			req, err := http.NewRequest(http.MethodGet, "https://photoslibrary.googleapis.com/v1/albums/a", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			responseModel := map[string]interface{}{}
+			result := c.fetchAttempt(req, &responseModel, &call_options.ResponseMeta{}, call_options.NewSettings())
+			if result.err != nil || responseModel["id"] != "a" {
+				t.Fatalf("unexpected result: %v, %v", responseModel, result.err)
+			}
+			if (result.responseBody != nil) != tt.expectBuffer {
+				t.Errorf("expected buffered body %v, got %q", tt.expectBuffer, result.responseBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,443 @@
+package test_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type LibraryAlbum struct {
+	ID                    string
+	Title                 string
+	CoverPhotoMediaItemId string
+	MediaItemIds          []string
+	// Share info returned by API, nil for albums that are not shared
+	ShareInfo map[string]interface{}
+	// Bodies of addEnrichment requests
+	Enrichments []json.RawMessage
+	// Joined shared album that wasn't added to library is listed only by sharedAlbums.list
+	SharedOnly bool
+}
+
+type LibraryMediaItem struct {
+	ID           string
+	Filename     string
+	CreationTime string
+	// Matched by contentFilter.includedContentCategories of search
+	Categories      []string
+	ContributorInfo map[string]string
+}
+
+type libraryFailure struct {
+	code   int
+	status string
+}
+
+// In-memory Google Photos library serving albums, shared albums and media items endpoints. Requests rejected by API
+// (unknown album or media item) are answered with the same errors as API does
+type Library struct {
+	t        *testing.T
+	m        sync.Mutex
+	albums   []*LibraryAlbum
+	items    []LibraryMediaItem
+	failures map[string]libraryFailure
+	requests []string
+	nextId   int
+	// Page size of list and search responses. Defaults to 50
+	PageSize int
+}
+
+func (l *Library) AddAlbums(albums ...LibraryAlbum) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	for i := range albums {
+		album := albums[i]
+		l.albums = append(l.albums, &album)
+	}
+}
+
+func (l *Library) AddMediaItems(items ...LibraryMediaItem) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.items = append(l.items, items...)
+}
+
+// Replaces media item with the same ID
+func (l *Library) UpdateMediaItem(item LibraryMediaItem) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if existing := l.findItem(item.ID); existing != nil {
+		*existing = item
+	}
+}
+
+// Returns copy of album or nil when it doesn't exist
+func (l *Library) Album(id string) *LibraryAlbum {
+	l.m.Lock()
+	defer l.m.Unlock()
+	album := l.findAlbum(id)
+	if album == nil {
+		return nil
+	}
+	result := *album
+	result.MediaItemIds = append([]string{}, album.MediaItemIds...)
+	return &result
+}
+
+// Returns titles of all albums in library order
+func (l *Library) AlbumTitles() []string {
+	l.m.Lock()
+	defer l.m.Unlock()
+	result := make([]string, 0, len(l.albums))
+	for _, album := range l.albums {
+		result = append(result, album.Title)
+	}
+	return result
+}
+
+func (l *Library) RemoveAlbum(id string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	for i, album := range l.albums {
+		if album.ID == id {
+			l.albums = append(l.albums[:i], l.albums[i+1:]...)
+			return
+		}
+	}
+}
+
+// Makes all following requests matching method and path (e.g. "POST", "/v1/albums/a:share") fail with code and status
+func (l *Library) Fail(method string, path string, code int, status string) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.failures[method+" "+path] = libraryFailure{code: code, status: status}
+}
+
+// Returns handled requests as "METHOD /path" strings
+func (l *Library) Requests() []string {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return append([]string{}, l.requests...)
+}
+
+// Returns number of handled requests matching method and path
+func (l *Library) CountRequests(method string, path string) int {
+	result := 0
+	for _, request := range l.Requests() {
+		if request == method+" "+path {
+			result++
+		}
+	}
+	return result
+}
+
+func (l *Library) findAlbum(id string) *LibraryAlbum {
+	for _, album := range l.albums {
+		if album.ID == id {
+			return album
+		}
+	}
+	return nil
+}
+
+func (l *Library) findItem(id string) *LibraryMediaItem {
+	for i := range l.items {
+		if l.items[i].ID == id {
+			return &l.items[i]
+		}
+	}
+	return nil
+}
+
+func (l *Library) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.requests = append(l.requests, r.Method+" "+r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	if failure, ok := l.failures[r.Method+" "+r.URL.Path]; ok {
+		writeApiError(w, failure.code, failure.status, "Injected failure.")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	switch {
+	case r.Method == http.MethodGet && path == "albums":
+		l.list(w, r, "albums", l.albumsJSON(false))
+	case r.Method == http.MethodGet && path == "sharedAlbums":
+		l.list(w, r, "sharedAlbums", l.albumsJSON(true))
+	case r.Method == http.MethodPost && path == "albums":
+		l.createAlbum(w, r)
+	case r.Method == http.MethodPost && path == "sharedAlbums:join":
+		l.join(w, r)
+	case r.Method == http.MethodGet && path == "mediaItems":
+		l.list(w, r, "mediaItems", l.itemsJSON(l.items))
+	case r.Method == http.MethodPost && path == "mediaItems:search":
+		l.search(w, r)
+	case strings.HasPrefix(path, "albums/"):
+		id := strings.TrimPrefix(path, "albums/")
+		method := ""
+		if i := strings.Index(id, ":"); i >= 0 {
+			id, method = id[:i], id[i+1:]
+		}
+		album := l.findAlbum(id)
+		if album == nil {
+			writeApiError(w, http.StatusNotFound, "NOT_FOUND", "Requested entity was not found.")
+			return
+		}
+		l.albumRequest(w, r, album, method)
+	default:
+		l.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		writeApiError(w, http.StatusNotFound, "NOT_FOUND", "Unexpected request.")
+	}
+}
+
+func (l *Library) albumRequest(w http.ResponseWriter, r *http.Request, album *LibraryAlbum, method string) {
+	body := struct {
+		MediaItemIds []string `json:"mediaItemIds"`
+	}{}
+	switch {
+	case r.Method == http.MethodGet && method == "":
+		writeJSON(w, albumJSON(album))
+	case r.Method == http.MethodPatch && method == "":
+		patch := struct {
+			Title                 string `json:"title"`
+			CoverPhotoMediaItemId string `json:"coverPhotoMediaItemId"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		for _, field := range strings.Split(r.URL.Query().Get("updateMask"), ",") {
+			switch field {
+			case "title":
+				album.Title = patch.Title
+			case "coverPhotoMediaItemId":
+				album.CoverPhotoMediaItemId = patch.CoverPhotoMediaItemId
+			}
+		}
+		writeJSON(w, albumJSON(album))
+	case r.Method == http.MethodPost && method == "batchAddMediaItems":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, id := range body.MediaItemIds {
+			if l.findItem(id) == nil {
+				writeApiError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Request contains an invalid media item id.")
+				return
+			}
+		}
+		album.MediaItemIds = append(album.MediaItemIds, body.MediaItemIds...)
+		writeJSON(w, struct{}{})
+	case r.Method == http.MethodPost && method == "batchRemoveMediaItems":
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		removed := make(map[string]bool)
+		for _, id := range body.MediaItemIds {
+			removed[id] = true
+		}
+		ids := make([]string, 0)
+		for _, id := range album.MediaItemIds {
+			if !removed[id] {
+				ids = append(ids, id)
+			}
+		}
+		album.MediaItemIds = ids
+		writeJSON(w, struct{}{})
+	case r.Method == http.MethodPost && method == "share":
+		options := struct {
+			SharedAlbumOptions map[string]bool `json:"sharedAlbumOptions"`
+		}{}
+		_ = json.NewDecoder(r.Body).Decode(&options)
+		album.ShareInfo = map[string]interface{}{
+			"sharedAlbumOptions": options.SharedAlbumOptions,
+			"shareableUrl":       "https://photos.app.goo.gl/" + album.ID,
+			"shareToken":         "token-" + album.ID,
+			"isJoined":           true,
+			"isOwned":            true,
+			"isJoinable":         true,
+		}
+		writeJSON(w, map[string]interface{}{"shareInfo": album.ShareInfo})
+	case r.Method == http.MethodPost && method == "unshare":
+		album.ShareInfo = nil
+		writeJSON(w, struct{}{})
+	case r.Method == http.MethodPost && method == "addEnrichment":
+		enrichment := json.RawMessage{}
+		_ = json.NewDecoder(r.Body).Decode(&enrichment)
+		album.Enrichments = append(album.Enrichments, enrichment)
+		writeJSON(w, map[string]interface{}{
+			"enrichmentItem": map[string]string{"id": fmt.Sprintf("enrichment-%d", len(album.Enrichments))},
+		})
+	default:
+		l.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		writeApiError(w, http.StatusNotFound, "NOT_FOUND", "Unexpected request.")
+	}
+}
+
+func (l *Library) createAlbum(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		Album struct {
+			Title string `json:"title"`
+		} `json:"album"`
+	}{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	l.nextId++
+	album := &LibraryAlbum{
+		ID:    fmt.Sprintf("created-%d", l.nextId),
+		Title: body.Album.Title,
+	}
+	l.albums = append(l.albums, album)
+	writeJSON(w, albumJSON(album))
+}
+
+func (l *Library) join(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		ShareToken string `json:"shareToken"`
+	}{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	for _, album := range l.albums {
+		if album.ShareInfo != nil && album.ShareInfo["shareToken"] == body.ShareToken {
+			album.ShareInfo["isJoined"] = true
+			writeJSON(w, map[string]interface{}{"album": albumJSON(album)})
+			return
+		}
+	}
+	writeApiError(w, http.StatusNotFound, "NOT_FOUND", "Requested entity was not found.")
+}
+
+func (l *Library) search(w http.ResponseWriter, r *http.Request) {
+	body := struct {
+		AlbumId   string `json:"albumId"`
+		PageToken string `json:"pageToken"`
+		Filters   *struct {
+			ContentFilter *struct {
+				IncludedContentCategories []string `json:"includedContentCategories"`
+			} `json:"contentFilter"`
+		} `json:"filters"`
+	}{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	items := make([]LibraryMediaItem, 0)
+	switch {
+	case body.AlbumId != "":
+		album := l.findAlbum(body.AlbumId)
+		if album == nil {
+			writeApiError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Invalid album id.")
+			return
+		}
+		for _, id := range album.MediaItemIds {
+			item := LibraryMediaItem{ID: id}
+			if found := l.findItem(id); found != nil {
+				item = *found
+			}
+			items = append(items, item)
+		}
+	case body.Filters != nil && body.Filters.ContentFilter != nil:
+		for _, item := range l.items {
+			if intersects(item.Categories, body.Filters.ContentFilter.IncludedContentCategories) {
+				items = append(items, item)
+			}
+		}
+	default:
+		items = l.items
+	}
+	l.writePage(w, "mediaItems", l.itemsJSON(items), body.PageToken)
+}
+
+func (l *Library) list(w http.ResponseWriter, r *http.Request, key string, values []interface{}) {
+	l.writePage(w, key, values, r.URL.Query().Get("pageToken"))
+}
+
+func (l *Library) writePage(w http.ResponseWriter, key string, values []interface{}, pageToken string) {
+	pageSize := l.PageSize
+	if pageSize == 0 {
+		pageSize = 50
+	}
+	start, _ := strconv.Atoi(pageToken)
+	end := start + pageSize
+	response := make(map[string]interface{})
+	if end < len(values) {
+		response["nextPageToken"] = strconv.Itoa(end)
+	} else {
+		end = len(values)
+	}
+	// API omits empty lists
+	if start < end {
+		response[key] = values[start:end]
+	}
+	writeJSON(w, response)
+}
+
+func (l *Library) albumsJSON(sharedOnly bool) []interface{} {
+	result := make([]interface{}, 0)
+	for _, album := range l.albums {
+		if sharedOnly && album.ShareInfo != nil || !sharedOnly && !album.SharedOnly {
+			result = append(result, albumJSON(album))
+		}
+	}
+	return result
+}
+
+func (l *Library) itemsJSON(items []LibraryMediaItem) []interface{} {
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value := map[string]interface{}{
+			"id":            item.ID,
+			"filename":      item.Filename,
+			"mediaMetadata": map[string]string{"creationTime": item.CreationTime},
+		}
+		if item.ContributorInfo != nil {
+			value["contributorInfo"] = item.ContributorInfo
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
+func albumJSON(album *LibraryAlbum) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":          album.ID,
+		"title":       album.Title,
+		"productUrl":  "https://photos.google.com/lr/album/" + album.ID,
+		"isWriteable": true,
+	}
+	// count is serialized as string and omitted for empty albums
+	if len(album.MediaItemIds) > 0 {
+		result["mediaItemsCount"] = strconv.Itoa(len(album.MediaItemIds))
+	}
+	if album.CoverPhotoMediaItemId != "" {
+		result["coverPhotoMediaItemId"] = album.CoverPhotoMediaItemId
+	}
+	if album.ShareInfo != nil {
+		result["shareInfo"] = album.ShareInfo
+	}
+	return result
+}
+
+func intersects(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeApiError(w http.ResponseWriter, code int, status string, message string) {
+	w.WriteHeader(code)
+	writeJSON(w, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+			"status":  status,
+		},
+	})
+}
+
+func NewLibrary(t *testing.T) *Library {
+	return &Library{
+		t:        t,
+		failures: make(map[string]libraryFailure),
+	}
+}
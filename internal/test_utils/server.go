@@ -0,0 +1,38 @@
+// Helpers for tests of services that talk to Google Photos API
+package test_utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type rewriteTransport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return t.next.RoundTrip(req)
+}
+
+// Starts server with given handler and returns client that sends all requests to it regardless of request host.
+// Server is closed when test finishes
+func NewClient(t *testing.T, handler http.Handler) *http.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("cannot parse server url: %v", err)
+	}
+	return &http.Client{
+		Transport: rewriteTransport{
+			target: target,
+			next:   server.Client().Transport,
+		},
+	}
+}
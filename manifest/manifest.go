@@ -0,0 +1,114 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+)
+
+// Desired state of albums
+type Manifest struct {
+	Albums []AlbumSpec `json:"albums"`
+}
+
+type AlbumSpec struct {
+	// ID of existing album. When empty album is matched by title
+	ID                    string `json:"id,omitempty"`
+	Title                 string `json:"title"`
+	CoverPhotoMediaItemId string `json:"coverPhotoMediaItemId,omitempty"`
+	// Album is shared with these options when it's not shared yet
+	Share *ShareSpec `json:"share,omitempty"`
+	// Enrichments are added only to newly created albums since API doesn't allow listing them
+	Enrichments []EnrichmentSpec `json:"enrichments,omitempty"`
+	// Album membership. When nil membership isn't managed
+	Members *MembersSpec `json:"members,omitempty"`
+}
+
+type ShareSpec struct {
+	IsCollaborative bool `json:"isCollaborative"`
+	IsCommentable   bool `json:"isCommentable"`
+}
+
+// Only one of fields should be set
+type EnrichmentSpec struct {
+	Text     string        `json:"text,omitempty"`
+	Location *LocationSpec `json:"location,omitempty"`
+	Map      *MapSpec      `json:"map,omitempty"`
+}
+
+type LocationSpec struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type MapSpec struct {
+	Origin      LocationSpec `json:"origin"`
+	Destination LocationSpec `json:"destination"`
+}
+
+// Album members are union of explicit IDs and results of all search filters
+type MembersSpec struct {
+	MediaItemIds []string                    `json:"mediaItemIds,omitempty"`
+	Filters      []media_items.SearchFilters `json:"filters,omitempty"`
+}
+
+// Parses manifest in YAML or JSON format. Field names are the same in both formats
+func Parse(data []byte) (*Manifest, error) {
+	// YAML is a superset of JSON. Decoded document is converted to JSON so json tags (also in nested
+	// media_items.SearchFilters) are used for both formats
+	var document interface{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert manifest: %w", err)
+	}
+	result := &Manifest{}
+	err = json.Unmarshal(jsonData, result)
+	if err != nil {
+		return nil, fmt.Errorf("cannot unmarshal manifest: %w", err)
+	}
+	err = result.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return result, nil
+}
+
+// Reads and parses manifest file
+func LoadFile(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest file: %w", err)
+	}
+	return Parse(data)
+}
+
+func (m Manifest) Validate() error {
+	for i, album := range m.Albums {
+		if album.ID == "" && album.Title == "" {
+			return fmt.Errorf("album #%d: either id or title is required", i)
+		}
+		for j, enrichment := range album.Enrichments {
+			count := 0
+			if enrichment.Text != "" {
+				count++
+			}
+			if enrichment.Location != nil {
+				count++
+			}
+			if enrichment.Map != nil {
+				count++
+			}
+			if count != 1 {
+				return fmt.Errorf("album #%d, enrichment #%d: exactly one of text, location or map is required", i, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package manifest
+
+import (
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const manifestYAML = `
+albums:
+  - title: Pets 2024
+    coverPhotoMediaItemId: cover
+    share:
+      isCollaborative: true
+    enrichments:
+      - text: Our pets
+      - location: {name: Kraków, latitude: 50.0614, longitude: 19.9366}
+      - map:
+          origin: {name: Kraków, latitude: 50.0614, longitude: 19.9366}
+          destination: {name: Zakopane, latitude: 49.2992, longitude: 19.9496}
+    members:
+      mediaItemIds: [a, b]
+      filters:
+        - contentFilter:
+            includedContentCategories: [PETS]
+          dateFilter:
+            dates: [{year: 2024}]
+  - id: album-id
+    title: Existing
+`
+
+const manifestJSON = `{"albums": [
+  {"title": "Pets 2024", "coverPhotoMediaItemId": "cover", "share": {"isCollaborative": true},
+    "enrichments": [
+      {"text": "Our pets"},
+      {"location": {"name": "Kraków", "latitude": 50.0614, "longitude": 19.9366}},
+      {"map": {"origin": {"name": "Kraków", "latitude": 50.0614, "longitude": 19.9366},
+        "destination": {"name": "Zakopane", "latitude": 49.2992, "longitude": 19.9496}}}
+    ],
+    "members": {"mediaItemIds": ["a", "b"], "filters": [
+      {"contentFilter": {"includedContentCategories": ["PETS"]}, "dateFilter": {"dates": [{"year": 2024}]}}
+    ]}},
+  {"id": "album-id", "title": "Existing"}
+]}`
+
+func TestParse(t *testing.T) {
+	krakow := LocationSpec{Name: "Kraków", Latitude: 50.0614, Longitude: 19.9366}
+	expected := &Manifest{Albums: []AlbumSpec{
+		{
+			Title:                 "Pets 2024",
+			CoverPhotoMediaItemId: "cover",
+			Share:                 &ShareSpec{IsCollaborative: true},
+			Enrichments: []EnrichmentSpec{
+				{Text: "Our pets"},
+				{Location: &krakow},
+				{Map: &MapSpec{Origin: krakow, Destination: LocationSpec{Name: "Zakopane", Latitude: 49.2992, Longitude: 19.9496}}},
+			},
+			Members: &MembersSpec{
+				MediaItemIds: []string{"a", "b"},
+				Filters: []media_items.SearchFilters{{
+					ContentFilter: &media_items.ContentFilter{IncludedContentCategories: []media_items.ContentCategory{"PETS"}},
+					DateFilter:    &media_items.DateFilter{Dates: []media_items.DateFilterDateItem{{Year: 2024}}},
+				}},
+			},
+		},
+		{ID: "album-id", Title: "Existing"},
+	}}
+	for name, data := range map[string]string{"yaml": manifestYAML, "json": manifestJSON} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := Parse([]byte(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed, expected) {
+				t.Errorf("expected %+v, got %+v", expected, parsed)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{"malformed yaml", "albums: [title: a", "cannot parse manifest"},
+		{"wrong type", "albums: {title: a}", "cannot unmarshal manifest"},
+		{"missing title and id", "albums:\n  - coverPhotoMediaItemId: a", "album #0: either id or title is required"},
+		{"empty enrichment", "albums:\n  - title: a\n    enrichments: [{}]", "album #0, enrichment #0: exactly one"},
+		{"multiple enrichments", "albums:\n  - title: a\n    enrichments:\n      - {text: a, location: {name: b}}", "album #0, enrichment #0: exactly one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected '%s' error, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "albums.yaml")
+	err := ioutil.WriteFile(path, []byte(manifestYAML), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loaded.Albums) != 2 || loaded.Albums[1].ID != "album-id" {
+		t.Errorf("unexpected manifest %+v", loaded)
+	}
+	_, err = LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil {
+		t.Error("expected error for missing file")
+	}
+}
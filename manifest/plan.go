@@ -0,0 +1,83 @@
+package manifest
+
+import (
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"strings"
+)
+
+type OperationType string
+
+const (
+	OperationCreate        OperationType = "create"
+	OperationPatch         OperationType = "patch"
+	OperationShare         OperationType = "share"
+	OperationAddEnrichment OperationType = "add-enrichment"
+	OperationAdd           OperationType = "add"
+	OperationRemove        OperationType = "remove"
+)
+
+// Single change needed to bring album to the state described in manifest
+type Operation struct {
+	Type OperationType
+	// Empty when album will be created by earlier operation in plan
+	AlbumId string
+	Title   string
+	// Patched fields and values (OperationPatch)
+	PatchFields []albums.Field
+	Album       albums.Album
+	// OperationShare
+	ShareOptions albums.SharedAlbumOptions
+	// OperationAdd and OperationRemove
+	MediaItemIds []string
+	// OperationAddEnrichment
+	Enrichment albums.NewEnrichmentItem
+	// Index of album in manifest. Used to pass ID of created album to following operations
+	albumIndex int
+}
+
+func (o Operation) String() string {
+	albumName := fmt.Sprintf("'%s'", o.Title)
+	if o.AlbumId != "" {
+		albumName += " (" + o.AlbumId + ")"
+	}
+	switch o.Type {
+	case OperationCreate:
+		return "create album " + albumName
+	case OperationPatch:
+		fields := make([]string, 0, len(o.PatchFields))
+		for _, field := range o.PatchFields {
+			fields = append(fields, string(field))
+		}
+		return fmt.Sprintf("patch album %s: %s", albumName, strings.Join(fields, ", "))
+	case OperationShare:
+		return fmt.Sprintf("share album %s (collaborative: %t, commentable: %t)", albumName, o.ShareOptions.IsCollaborative, o.ShareOptions.IsCommentable)
+	case OperationAddEnrichment:
+		return "add enrichment to album " + albumName
+	case OperationAdd:
+		return fmt.Sprintf("add %d media items to album %s", len(o.MediaItemIds), albumName)
+	case OperationRemove:
+		return fmt.Sprintf("remove %d media items from album %s", len(o.MediaItemIds), albumName)
+	}
+	return fmt.Sprintf("%s album %s", o.Type, albumName)
+}
+
+// List of operations in order they will be applied
+type Plan struct {
+	Operations []Operation
+}
+
+func (p Plan) IsEmpty() bool {
+	return len(p.Operations) == 0
+}
+
+func (p Plan) String() string {
+	if p.IsEmpty() {
+		return "no changes"
+	}
+	lines := make([]string, 0, len(p.Operations))
+	for _, operation := range p.Operations {
+		lines = append(lines, operation.String())
+	}
+	return strings.Join(lines, "\n")
+}
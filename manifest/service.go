@@ -0,0 +1,240 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/media_items"
+)
+
+// Plans and applies changes described by manifest using albums and media items services
+type Manager struct {
+	albums     albums.AlbumsService
+	mediaItems media_items.MediaItemsService
+}
+
+// Computes operations needed to bring albums to the state described in manifest. Albums are matched by ID and
+// by title when ID is not specified. Nothing is changed
+func (m Manager) Plan(manifest Manifest, ctx context.Context) (*Plan, error) {
+	err := manifest.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	plan := &Plan{
+		Operations: make([]Operation, 0),
+	}
+	// albums are listed once and only when some spec has to be matched by title
+	var albumsByTitle map[string][]albums.Album
+	for i, spec := range manifest.Albums {
+		var album *albums.Album
+		if spec.ID != "" {
+			album, err = m.albums.Get(spec.ID, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get album '%s': %w", spec.ID, err)
+			}
+		} else {
+			if albumsByTitle == nil {
+				albumsByTitle, err = m.listAlbumsByTitle(ctx)
+				if err != nil {
+					return nil, err
+				}
+			}
+			album, err = findAlbumByTitle(albumsByTitle, spec.Title)
+			if err != nil {
+				return nil, err
+			}
+		}
+		operations, err := m.planAlbum(i, spec, album, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot plan album '%s': %w", spec.Title, err)
+		}
+		plan.Operations = append(plan.Operations, operations...)
+	}
+	return plan, nil
+}
+
+func (m Manager) listAlbumsByTitle(ctx context.Context) (map[string][]albums.Album, error) {
+	allAlbums, err := m.albums.ListAll(nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list albums: %w", err)
+	}
+	result := make(map[string][]albums.Album)
+	for _, album := range allAlbums {
+		result[album.Title] = append(result[album.Title], album)
+	}
+	return result, nil
+}
+
+func findAlbumByTitle(albumsByTitle map[string][]albums.Album, title string) (*albums.Album, error) {
+	existingAlbums := albumsByTitle[title]
+	switch len(existingAlbums) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &existingAlbums[0], nil
+	}
+	return nil, fmt.Errorf("multiple albums titled '%s', specify album id", title)
+}
+
+func (m Manager) planAlbum(index int, spec AlbumSpec, album *albums.Album, ctx context.Context) ([]Operation, error) {
+	operations := make([]Operation, 0)
+	newOperation := func(operationType OperationType) Operation {
+		operation := Operation{
+			Type:       operationType,
+			Title:      spec.Title,
+			albumIndex: index,
+		}
+		if album != nil {
+			operation.AlbumId = album.ID
+			if spec.Title == "" {
+				operation.Title = album.Title
+			}
+		}
+		return operation
+	}
+	if album == nil {
+		operations = append(operations, newOperation(OperationCreate))
+	}
+	if spec.Share != nil && (album == nil || !album.IsShared()) {
+		operation := newOperation(OperationShare)
+		operation.ShareOptions = albums.SharedAlbumOptions{
+			IsCollaborative: spec.Share.IsCollaborative,
+			IsCommentable:   spec.Share.IsCommentable,
+		}
+		operations = append(operations, operation)
+	}
+	if spec.Members != nil {
+		desiredIds, err := m.desiredMembers(*spec.Members, ctx)
+		if err != nil {
+			return nil, err
+		}
+		toAdd := desiredIds
+		if album != nil {
+			diff, err := m.albums.Reconcile(album.ID, desiredIds, &albums.ReconcileOptions{PlanOnly: true}, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("cannot compare album items: %w", err)
+			}
+			toAdd = diff.ToAdd
+			if len(diff.ToRemove) > 0 {
+				operation := newOperation(OperationRemove)
+				operation.MediaItemIds = diff.ToRemove
+				operations = append(operations, operation)
+			}
+		}
+		if len(toAdd) > 0 {
+			operation := newOperation(OperationAdd)
+			operation.MediaItemIds = toAdd
+			operations = append(operations, operation)
+		}
+	}
+	if album == nil {
+		for _, enrichmentSpec := range spec.Enrichments {
+			operation := newOperation(OperationAddEnrichment)
+			operation.Enrichment = enrichmentSpec.toEnrichment()
+			operations = append(operations, operation)
+		}
+	}
+	patch := newOperation(OperationPatch)
+	if album != nil {
+		patch.Album = *album
+	}
+	if album != nil && spec.Title != "" && album.Title != spec.Title {
+		patch.Album.Title = spec.Title
+		patch.PatchFields = append(patch.PatchFields, albums.AlbumFieldTitle)
+	}
+	// cover photo has to be in album so it's patched after adding items
+	if spec.CoverPhotoMediaItemId != "" && (album == nil || album.CoverPhotoMediaItemID != spec.CoverPhotoMediaItemId) {
+		patch.Album.CoverPhotoMediaItemID = spec.CoverPhotoMediaItemId
+		patch.PatchFields = append(patch.PatchFields, albums.AlbumFieldCoverPhotoMediaItemId)
+	}
+	if len(patch.PatchFields) > 0 {
+		operations = append(operations, patch)
+	}
+	return operations, nil
+}
+
+func (m Manager) desiredMembers(spec MembersSpec, ctx context.Context) ([]string, error) {
+	result := make([]string, 0, len(spec.MediaItemIds))
+	result = append(result, spec.MediaItemIds...)
+	for i := range spec.Filters {
+		items, err := m.mediaItems.SearchAll(&media_items.SearchOptions{
+			PageSize: 100,
+			Filters:  &spec.Filters[i],
+		}, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot search media items for filter #%d: %w", i, err)
+		}
+		for _, item := range items {
+			result = append(result, item.ID)
+		}
+	}
+	return result, nil
+}
+
+func (s LocationSpec) toLocation() albums.Location {
+	return albums.Location{
+		LocationName: s.Name,
+		LatLng: albums.LatLng{
+			Latitude:  s.Latitude,
+			Longitude: s.Longitude,
+		},
+	}
+}
+
+func (s EnrichmentSpec) toEnrichment() albums.NewEnrichmentItem {
+	switch {
+	case s.Location != nil:
+		return albums.NewLocationEnrichment(s.Location.toLocation())
+	case s.Map != nil:
+		return albums.NewMapEnrichment(s.Map.Origin.toLocation(), s.Map.Destination.toLocation())
+	}
+	return albums.NewTextEnrichment(s.Text)
+}
+
+// Executes operations from plan. Stops at first failed operation
+func (m Manager) Apply(plan Plan, ctx context.Context) error {
+	createdAlbumIds := make(map[int]string)
+	for _, operation := range plan.Operations {
+		if operation.AlbumId == "" {
+			operation.AlbumId = createdAlbumIds[operation.albumIndex]
+		}
+		err := m.applyOperation(operation, createdAlbumIds, ctx)
+		if err != nil {
+			return fmt.Errorf("cannot %s: %w", operation, err)
+		}
+	}
+	return nil
+}
+
+func (m Manager) applyOperation(operation Operation, createdAlbumIds map[int]string, ctx context.Context) error {
+	var err error
+	switch operation.Type {
+	case OperationCreate:
+		var album *albums.Album
+		album, err = m.albums.Create(operation.Title, ctx)
+		if err == nil {
+			createdAlbumIds[operation.albumIndex] = album.ID
+		}
+	case OperationPatch:
+		operation.Album.ID = operation.AlbumId
+		_, err = m.albums.Patch(operation.Album, operation.PatchFields, ctx)
+	case OperationShare:
+		_, err = m.albums.Share(operation.AlbumId, operation.ShareOptions, ctx)
+	case OperationAddEnrichment:
+		_, err = m.albums.AddEnrichment(operation.AlbumId, operation.Enrichment, albums.NewLastInAlbumPosition(), ctx)
+	case OperationAdd:
+		_, err = m.albums.BatchAddMediaItemsAll(operation.AlbumId, operation.MediaItemIds, nil, ctx)
+	case OperationRemove:
+		_, err = m.albums.BatchRemoveMediaItemsAll(operation.AlbumId, operation.MediaItemIds, nil, ctx)
+	default:
+		err = fmt.Errorf("unknown operation type '%s'", operation.Type)
+	}
+	return err
+}
+
+func NewManager(albumsService albums.AlbumsService, mediaItemsService media_items.MediaItemsService) Manager {
+	return Manager{
+		albums:     albumsService,
+		mediaItems: mediaItemsService,
+	}
+}
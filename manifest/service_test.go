@@ -0,0 +1,202 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPlanListsAlbumsOnce(t *testing.T) {
+	listRequests := int32(0)
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/albums" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&listRequests, 1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"albums": []albums.Album{
+				{ID: "a", Title: "A"},
+				{ID: "b", Title: "B"},
+				{ID: "d1", Title: "Duplicated"},
+				{ID: "d2", Title: "Duplicated"},
+			},
+		})
+	}))
+	m := NewManager(albums.NewHttpAlbumsService(client), nil)
+	plan, err := m.Plan(Manifest{Albums: []AlbumSpec{
+		{Title: "A"},
+		{Title: "B", CoverPhotoMediaItemId: "item"},
+		{Title: "C"},
+	}}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&listRequests); got != 1 {
+		t.Errorf("expected albums to be listed once, got %d requests", got)
+	}
+	expected := []struct {
+		operationType OperationType
+		albumId       string
+	}{
+		{OperationPatch, "b"},
+		{OperationCreate, ""},
+	}
+	if len(plan.Operations) != len(expected) {
+		t.Fatalf("expected %d operations, got %s", len(expected), plan)
+	}
+	for i, operation := range plan.Operations {
+		if operation.Type != expected[i].operationType || operation.AlbumId != expected[i].albumId {
+			t.Errorf("operation %d: expected %s of '%s', got %s of '%s'", i, expected[i].operationType, expected[i].albumId, operation.Type, operation.AlbumId)
+		}
+	}
+
+	_, err = m.Plan(Manifest{Albums: []AlbumSpec{{Title: "Duplicated"}}}, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "multiple albums titled 'Duplicated'") {
+		t.Errorf("expected duplicated title error, got %v", err)
+	}
+}
+
+const libraryManifest = `
+albums:
+  - title: Pets
+    coverPhotoMediaItemId: a
+    share: {isCollaborative: true}
+    enrichments:
+      - text: Our pets
+    members:
+      mediaItemIds: [a]
+      filters:
+        - contentFilter: {includedContentCategories: [PETS]}
+  - title: Trip
+    coverPhotoMediaItemId: c
+    enrichments:
+      - text: Not added to existing album
+    members:
+      mediaItemIds: [b, c]
+  - id: old
+    title: Renamed
+  - title: Done
+    members:
+      mediaItemIds: [a]
+`
+
+func newTestManager(t *testing.T) (Manager, *test_utils.Library) {
+	library := test_utils.NewLibrary(t)
+	library.AddMediaItems(
+		test_utils.LibraryMediaItem{ID: "a"},
+		test_utils.LibraryMediaItem{ID: "b"},
+		test_utils.LibraryMediaItem{ID: "c"},
+		test_utils.LibraryMediaItem{ID: "dog", Categories: []string{"PETS"}},
+	)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "trip", Title: "Trip", CoverPhotoMediaItemId: "a", MediaItemIds: []string{"a", "b"}},
+		test_utils.LibraryAlbum{ID: "old", Title: "Old title"},
+		test_utils.LibraryAlbum{ID: "done", Title: "Done", MediaItemIds: []string{"a"}},
+	)
+	client := test_utils.NewClient(t, library)
+	return NewManager(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil)), library
+}
+
+func TestPlanAndApply(t *testing.T) {
+	m, library := newTestManager(t)
+	parsed, err := Parse([]byte(libraryManifest))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plan, err := m.Plan(*parsed, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		// items are added before cover photo is set
+		"create album 'Pets'",
+		"share album 'Pets' (collaborative: true, commentable: false)",
+		"add 2 media items to album 'Pets'",
+		"add enrichment to album 'Pets'",
+		"patch album 'Pets': coverPhotoMediaItemId",
+		"remove 1 media items from album 'Trip' (trip)",
+		"add 1 media items to album 'Trip' (trip)",
+		"patch album 'Trip' (trip): coverPhotoMediaItemId",
+		"patch album 'Renamed' (old): title",
+	}
+	if plan.String() != strings.Join(expected, "\n") {
+		t.Fatalf("expected plan:\n%s\ngot:\n%s", strings.Join(expected, "\n"), plan)
+	}
+	if got := library.CountRequests("POST", "/v1/albums"); got != 0 {
+		t.Errorf("plan shouldn't change library, got %d create requests", got)
+	}
+
+	err = m.Apply(*plan, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	created := make([]string, 0)
+	for _, request := range library.Requests() {
+		if strings.HasPrefix(request, "POST /v1/albums") || strings.HasPrefix(request, "PATCH /v1/albums/created-1") {
+			created = append(created, request)
+		}
+	}
+	expectedRequests := []string{
+		"POST /v1/albums",
+		"POST /v1/albums/created-1:share",
+		"POST /v1/albums/created-1:batchAddMediaItems",
+		"POST /v1/albums/created-1:addEnrichment",
+		"PATCH /v1/albums/created-1",
+		"POST /v1/albums/trip:batchRemoveMediaItems",
+		"POST /v1/albums/trip:batchAddMediaItems",
+	}
+	if !reflect.DeepEqual(created, expectedRequests) {
+		t.Errorf("expected requests %v, got %v", expectedRequests, created)
+	}
+	pets := library.Album("created-1")
+	if pets.Title != "Pets" || pets.ShareInfo == nil || pets.CoverPhotoMediaItemId != "a" ||
+		!reflect.DeepEqual(pets.MediaItemIds, []string{"a", "dog"}) || len(pets.Enrichments) != 1 {
+		t.Errorf("unexpected created album %+v", pets)
+	}
+	trip := library.Album("trip")
+	if trip.CoverPhotoMediaItemId != "c" || !reflect.DeepEqual(trip.MediaItemIds, []string{"b", "c"}) || len(trip.Enrichments) != 0 {
+		t.Errorf("unexpected updated album %+v", trip)
+	}
+	if title := library.Album("old").Title; title != "Renamed" {
+		t.Errorf("expected album to be renamed, got '%s'", title)
+	}
+
+	plan, err = m.Plan(*parsed, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("expected no changes after apply, got:\n%s", plan)
+	}
+}
+
+func TestApplyStopsAtFailedOperation(t *testing.T) {
+	m, library := newTestManager(t)
+	library.Fail("POST", "/v1/albums/created-1:share", 500, "INTERNAL")
+	plan, err := m.Plan(Manifest{Albums: []AlbumSpec{
+		{Title: "Shared", Share: &ShareSpec{}, Members: &MembersSpec{MediaItemIds: []string{"a"}}},
+		{ID: "old", Title: "Renamed"},
+	}}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = m.Apply(*plan, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "cannot share album 'Shared' (created-1)") {
+		t.Fatalf("expected share error with created album id, got %v", err)
+	}
+	if items := library.Album("created-1").MediaItemIds; len(items) != 0 {
+		t.Errorf("operations after failed one shouldn't be applied, got items %v", items)
+	}
+	if title := library.Album("old").Title; title != "Old title" {
+		t.Errorf("following albums shouldn't be changed, got '%s'", title)
+	}
+}
@@ -0,0 +1,59 @@
+package media_items
+
+import (
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"unicode/utf8"
+)
+
+const maxDescriptionLength = 1000
+
+// Returned by PatchDiff when there is nothing to patch
+var ErrNoChanges = internal.ErrNoChanges
+
+var patchableFields = map[string]Field{
+	string(MediaItemFieldDescription): MediaItemFieldDescription,
+}
+
+// Computes update mask for Patch from fields that differ between old and new media item. Returns error when fields
+// that cannot be patched were changed or new values are invalid and ErrNoChanges when nothing changed
+func PatchDiff(old MediaItem, new MediaItem) ([]Field, error) {
+	if old.ID != new.ID {
+		return nil, errors.New("media item id cannot be changed")
+	}
+	result := make([]Field, 0)
+	for _, name := range internal.DiffFields(old, new) {
+		field, ok := patchableFields[name]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' cannot be patched", name)
+		}
+		result = append(result, field)
+	}
+	if len(result) == 0 {
+		return nil, ErrNoChanges
+	}
+	err := validatePatch(new, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Validates values of fields in update mask. Empty mask means that all fields are updated
+func validatePatch(mediaItem MediaItem, updateMask []Field) error {
+	if len(updateMask) == 0 {
+		updateMask = []Field{MediaItemFieldDescription}
+	}
+	for _, field := range updateMask {
+		switch field {
+		case MediaItemFieldDescription:
+			if utf8.RuneCountInString(mediaItem.Description) > maxDescriptionLength {
+				return fmt.Errorf("description cannot be longer than %d characters", maxDescriptionLength)
+			}
+		default:
+			return fmt.Errorf("field '%s' cannot be patched", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package media_items
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPatchDiff(t *testing.T) {
+	old := MediaItem{ID: "item", Description: "Description"}
+	tests := []struct {
+		name          string
+		new           MediaItem
+		expected      []Field
+		expectedError string
+	}{
+		{"description", MediaItem{ID: "item", Description: "New description"}, []Field{MediaItemFieldDescription}, ""},
+		{"no changes", old, nil, ErrNoChanges.Error()},
+		{"changed id", MediaItem{ID: "other", Description: "Description"}, nil, "media item id cannot be changed"},
+		{"not patchable field", MediaItem{ID: "item", Description: "Description", Filename: "file.jpg"}, nil, "cannot be patched"},
+		{"too long description", MediaItem{ID: "item", Description: strings.Repeat("a", 1001)}, nil, "description cannot be longer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PatchDiff(old, tt.new)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+	if _, err := PatchDiff(old, old); !errors.Is(err, ErrNoChanges) {
+		t.Errorf("expected ErrNoChanges, got %v", err)
+	}
+}
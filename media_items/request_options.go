@@ -65,3 +65,12 @@ type NewMediaItem struct {
 	Description     string          `json:"description"`
 	SimpleMediaItem SimpleMediaItem `json:"simpleMediaItem"`
 }
+
+type BatchGetOptions struct {
+	// Number of 50 items chunks fetched in parallel. Defaults to 1
+	Concurrency int
+	// Emit items in the same order as requested ids. Matters only when Concurrency is greater than 1
+	PreserveOrder bool
+	// Fetch every id only once even if it's repeated in requested ids
+	Deduplicate bool
+}
@@ -1,6 +1,7 @@
 package media_items
 
 import (
+	"fmt"
 	"github.com/duffpl/google-photos-api-client/internal"
 )
 
@@ -27,6 +28,30 @@ type MediaItem struct {
 type MediaItemWithStatus struct {
 	MediaItem MediaItem          `json:"mediaItem"`
 	Status    internal.APIStatus `json:"status"`
+	// ID that was sent in batchGet request. Failed results don't contain media item so it's the only way to tell
+	// which item failed
+	requestedId string
+}
+
+// Returns *MediaItemError when media item couldn't be fetched, nil otherwise
+func (m MediaItemWithStatus) Err() error {
+	if m.Status.Code == 0 {
+		return nil
+	}
+	return &MediaItemError{
+		MediaItemId: m.requestedId,
+		Status:      m.Status,
+	}
+}
+
+// Error for single media item that failed in batch request
+type MediaItemError struct {
+	MediaItemId string
+	Status      internal.APIStatus
+}
+
+func (e *MediaItemError) Error() string {
+	return fmt.Sprintf("media item '%s' failed: %s (%d)", e.MediaItemId, e.Status.Message, e.Status.Code)
 }
 
 type MediaMetadata struct {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
 	"github.com/duffpl/google-photos-api-client/internal"
 	"github.com/duffpl/google-photos-api-client/uploader"
 	"github.com/imdario/mergo"
@@ -12,23 +14,24 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 )
 
 // Interface for https://developers.google.com/photos/library/reference/rest/v1/mediaItems resource
 type MediaItemsService interface {
-	BatchCreateItems(options BatchCreateOptions, ctx context.Context) ([]NewMediaItemResult, error)
-	BatchCreateItemsFromFiles(albumId string, paths []string, position albums.AlbumPosition, ctx context.Context) ([]NewMediaItemResult, error)
-	BatchGetItems(ids []string, ctx context.Context) (mediaItems []MediaItemWithStatus, err error)
-	BatchGetItemsAll(ids []string, ctx context.Context) ([]MediaItemWithStatus, error)
-	BatchGetItemsAllAsync(ids []string, ctx context.Context) (<-chan MediaItemWithStatus, <-chan error)
-	Get(itemId string, ctx context.Context) (mediaItem *MediaItem, err error)
-	List(options *ListOptions, pageToken string, ctx context.Context) (mediaItems []MediaItem, nextPageToken string, err error)
-	ListAll(options *ListOptions, ctx context.Context) ([]MediaItem, error)
-	ListAllAsync(options *ListOptions, ctx context.Context) (<-chan MediaItem, <-chan error)
-	Patch(mediaItem MediaItem, updateMask []Field, ctx context.Context) (*MediaItem, error)
-	Search(options *SearchOptions, pageToken string, ctx context.Context) (mediaItems []MediaItem, nextPageToken string, err error)
-	SearchAll(options *SearchOptions, ctx context.Context) ([]MediaItem, error)
-	SearchAllAsync(options *SearchOptions, ctx context.Context) (<-chan MediaItem, <-chan error)
+	BatchCreateItems(options BatchCreateOptions, ctx context.Context, opts ...call_options.CallOption) ([]NewMediaItemResult, error)
+	BatchCreateItemsFromFiles(albumId string, paths []string, position albums.AlbumPosition, ctx context.Context, opts ...call_options.CallOption) ([]NewMediaItemResult, error)
+	BatchGetItems(ids []string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItemWithStatus, err error)
+	BatchGetItemsAll(ids []string, options *BatchGetOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItemWithStatus, error)
+	BatchGetItemsAllAsync(ids []string, options *BatchGetOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItemWithStatus, <-chan error)
+	Get(itemId string, ctx context.Context, opts ...call_options.CallOption) (mediaItem *MediaItem, err error)
+	List(options *ListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItem, nextPageToken string, err error)
+	ListAll(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItem, error)
+	ListAllAsync(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItem, <-chan error)
+	Patch(mediaItem MediaItem, updateMask []Field, ctx context.Context, opts ...call_options.CallOption) (*MediaItem, error)
+	Search(options *SearchOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItem, nextPageToken string, err error)
+	SearchAll(options *SearchOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItem, error)
+	SearchAllAsync(options *SearchOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItem, <-chan error)
 }
 
 type HttpMediaItemsService struct {
@@ -37,10 +40,14 @@ type HttpMediaItemsService struct {
 	path string
 }
 
-// Patches MediaItem. updateMask argument can be used to update only selected fields. Currently only id and description fields are read
+// Patches MediaItem. updateMask argument can be used to update only selected fields (see PatchDiff). Currently only id and description fields are read
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/patch
-func (s HttpMediaItemsService) Patch(mediaItem MediaItem, updateMask []Field, ctx context.Context) (*MediaItem, error) {
+func (s HttpMediaItemsService) Patch(mediaItem MediaItem, updateMask []Field, ctx context.Context, opts ...call_options.CallOption) (*MediaItem, error) {
+	err := validatePatch(mediaItem, updateMask)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media item patch: %w", err)
+	}
 	responseModel := &MediaItem{}
 	queryValues := url.Values{}
 	if len(updateMask) > 0 {
@@ -50,7 +57,7 @@ func (s HttpMediaItemsService) Patch(mediaItem MediaItem, updateMask []Field, ct
 		}
 		queryValues["updateMask"] = []string{strings.Join(fields, ",")}
 	}
-	err := s.c.PatchJSON(s.path+"/"+mediaItem.ID, queryValues, mediaItem, responseModel, nil, ctx)
+	err = s.c.PatchJSON(s.path+"/"+mediaItem.ID, queryValues, mediaItem, responseModel, nil, ctx, internal.Idempotent(opts)...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,21 +67,28 @@ func (s HttpMediaItemsService) Patch(mediaItem MediaItem, updateMask []Field, ct
 // Create one or multiple media items
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/batchCreate
-func (s HttpMediaItemsService) BatchCreateItems(options BatchCreateOptions, ctx context.Context) ([]NewMediaItemResult, error) {
+func (s HttpMediaItemsService) BatchCreateItems(options BatchCreateOptions, ctx context.Context, opts ...call_options.CallOption) ([]NewMediaItemResult, error) {
+	if options.AlbumPosition.Position != "" {
+		err := options.AlbumPosition.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid album position: %w", err)
+		}
+	}
 	responseModel := &batchCreateResponse{}
-	err := s.c.PostJSON(s.path+":batchCreate", nil, options, responseModel, nil, ctx)
+	err := s.c.PostJSON(s.path+":batchCreate", nil, options, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot complete request: %w", err)
 	}
 	return responseModel.NewMediaItemResults, nil
 }
 
-// Extension of BatchCreateItems for easier uploading (at this moment it's limited by
-func (s HttpMediaItemsService) BatchCreateItemsFromFiles(albumId string, paths []string, position albums.AlbumPosition, ctx context.Context) ([]NewMediaItemResult, error) {
+// Extension of BatchCreateItems for easier uploading. Files are uploaded one by one with MediaUploader, call options
+// apply to uploads and batchCreate request
+func (s HttpMediaItemsService) BatchCreateItemsFromFiles(albumId string, paths []string, position albums.AlbumPosition, ctx context.Context, opts ...call_options.CallOption) ([]NewMediaItemResult, error) {
 	mediaItems := make([]NewMediaItem, 0)
 	for _, filePath := range paths {
 		fileName := path.Base(filePath)
-		token, err := s.u.UploadFile(filePath, ctx)
+		token, err := s.u.UploadFile(filePath, ctx, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("cannot upload file '%s': %w", fileName, err)
 		}
@@ -89,7 +103,7 @@ func (s HttpMediaItemsService) BatchCreateItemsFromFiles(albumId string, paths [
 		AlbumId:       albumId,
 		AlbumPosition: position,
 		NewMediaItems: mediaItems,
-	}, ctx)
+	}, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create items: %w", err)
 	}
@@ -99,40 +113,50 @@ func (s HttpMediaItemsService) BatchCreateItemsFromFiles(albumId string, paths [
 // Fetches media item specified by ID
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/get
-func (s HttpMediaItemsService) Get(itemId string, ctx context.Context) (mediaItem *MediaItem, err error) {
+func (s HttpMediaItemsService) Get(itemId string, ctx context.Context, opts ...call_options.CallOption) (mediaItem *MediaItem, err error) {
 	q := url.Values{"mediaItemId": []string{itemId}}
 	responseModel := &MediaItem{}
-	err = s.c.FetchWithGet(s.path, q, responseModel, nil, ctx)
+	err = s.c.FetchWithGet(s.path, q, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot complete request: %w", err)
 	}
 	return responseModel, nil
 }
 
-// Fetches multiple media items (max 50)
+// Fetches multiple media items (max 50). Results that failed can be checked with MediaItemWithStatus.Err
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/batchGet
-func (s HttpMediaItemsService) BatchGetItems(ids []string, ctx context.Context) (mediaItems []MediaItemWithStatus, err error) {
+func (s HttpMediaItemsService) BatchGetItems(ids []string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItemWithStatus, err error) {
 	if len(ids) > 50 {
 		return nil, errors.New("max 50 ids allowed")
 	}
 	q := url.Values{"mediaItemIds": ids}
 	responseModel := &batchGetMediaItemsResponse{}
-	err = s.c.FetchWithGet(s.path+":batchGet", q, responseModel, nil, ctx)
+	err = s.c.FetchWithGet(s.path+":batchGet", q, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot complete request: %w", err)
 	}
+	// results are returned in the same order as requested ids
+	for i := range responseModel.MediaItemResults {
+		if i < len(ids) {
+			responseModel.MediaItemResults[i].requestedId = ids[i]
+		}
+	}
 	return responseModel.MediaItemResults, nil
 }
 
 // Synchronous wrapper for BatchGetItemsAllAsync
-func (s HttpMediaItemsService) BatchGetItemsAll(ids []string, ctx context.Context) ([]MediaItemWithStatus, error) {
-	itemsC, errorsC := s.BatchGetItemsAllAsync(ids, ctx)
+func (s HttpMediaItemsService) BatchGetItemsAll(ids []string, options *BatchGetOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItemWithStatus, error) {
+	itemsC, errorsC := s.BatchGetItemsAllAsync(ids, options, ctx, opts...)
 	result := make([]MediaItemWithStatus, 0)
 	for {
 		select {
 		case item, ok := <-itemsC:
 			if !ok {
+				// items channel is closed without error when ctx is cancelled
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
 				return result, nil
 			}
 			result = append(result, item)
@@ -142,34 +166,102 @@ func (s HttpMediaItemsService) BatchGetItemsAll(ids []string, ctx context.Contex
 	}
 }
 
+type batchGetChunkResult struct {
+	index int
+	items []MediaItemWithStatus
+	err   error
+}
+
 // Asynchronous wrapper for BatchGetItems
-// Fetches any number of media items in 50 items chunks.
-func (s HttpMediaItemsService) BatchGetItemsAllAsync(ids []string, ctx context.Context) (<-chan MediaItemWithStatus, <-chan error) {
+// Fetches any number of media items in 50 items chunks. Chunks are fetched by options.Concurrency workers
+// (1 by default). Items are emitted in the order their chunks were fetched unless options.PreserveOrder is set.
+func (s HttpMediaItemsService) BatchGetItemsAllAsync(ids []string, options *BatchGetOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItemWithStatus, <-chan error) {
+	requestOptions := BatchGetOptions{
+		Concurrency: 1,
+	}
+	if options != nil {
+		_ = mergo.Merge(&requestOptions, options, mergo.WithOverride)
+	}
+	if requestOptions.Concurrency < 1 {
+		requestOptions.Concurrency = 1
+	}
+	if requestOptions.Deduplicate {
+		ids = internal.UniqueStrings(ids)
+	}
+	chunks := internal.ChunkStrings(ids, 50)
 	itemsC := make(chan MediaItemWithStatus)
 	errC := make(chan error)
 	go func() {
 		defer close(itemsC)
-		itemsPerChunk := 50
-		chunkCount := (len(ids) / itemsPerChunk) + 1
-		for i := 0; i < chunkCount; i++ {
-			select {
-			case <-ctx.Done():
-				return
-			default:
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		chunkIndexesC := make(chan int)
+		resultsC := make(chan batchGetChunkResult)
+		go func() {
+			defer close(chunkIndexesC)
+			for i := range chunks {
+				select {
+				case <-ctx.Done():
+					return
+				case chunkIndexesC <- i:
+				}
 			}
-			sliceStart := i * itemsPerChunk
-			sliceEnd := internal.Min((i+1)*itemsPerChunk, len(ids))
-			idsChunk := ids[sliceStart:sliceEnd]
-			mediaItems, err := s.BatchGetItems(idsChunk, ctx)
-			if err != nil {
-				errC <- err
-				return
+		}()
+		wg := sync.WaitGroup{}
+		for w := 0; w < internal.Min(requestOptions.Concurrency, len(chunks)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range chunkIndexesC {
+					items, err := s.BatchGetItems(chunks[i], ctx, opts...)
+					select {
+					case <-ctx.Done():
+						return
+					case resultsC <- batchGetChunkResult{index: i, items: items, err: err}:
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(resultsC)
+		}()
+		emit := func(items []MediaItemWithStatus) bool {
+			for _, item := range items {
+				select {
+				case <-ctx.Done():
+					return false
+				case itemsC <- item:
+				}
 			}
-			for _, item := range mediaItems {
+			return true
+		}
+		pendingChunks := make(map[int][]MediaItemWithStatus)
+		nextChunk := 0
+		for result := range resultsC {
+			if result.err != nil {
 				select {
 				case <-ctx.Done():
+				case errC <- result.err:
+				}
+				return
+			}
+			if !requestOptions.PreserveOrder {
+				if !emit(result.items) {
+					return
+				}
+				continue
+			}
+			pendingChunks[result.index] = result.items
+			for {
+				items, ok := pendingChunks[nextChunk]
+				if !ok {
+					break
+				}
+				delete(pendingChunks, nextChunk)
+				nextChunk++
+				if !emit(items) {
 					return
-				case itemsC <- item:
 				}
 			}
 		}
@@ -180,7 +272,7 @@ func (s HttpMediaItemsService) BatchGetItemsAllAsync(ids []string, ctx context.C
 // Fetches all media items. Default page size is 50
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/list
-func (s HttpMediaItemsService) List(options *ListOptions, pageToken string, ctx context.Context) (mediaItems []MediaItem, nextPageToken string, err error) {
+func (s HttpMediaItemsService) List(options *ListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItem, nextPageToken string, err error) {
 	responseModel := &mediaItemsResponse{}
 	requestOptions := ListOptions{
 		PageSize: 50,
@@ -195,7 +287,7 @@ func (s HttpMediaItemsService) List(options *ListOptions, pageToken string, ctx
 		requestOptions,
 		pageToken,
 	}
-	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx)
+	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx, internal.RequireFields(opts, "nextPageToken")...)
 	if err != nil {
 		return nil, "", fmt.Errorf("cannot complete request: %w", err)
 	}
@@ -203,8 +295,8 @@ func (s HttpMediaItemsService) List(options *ListOptions, pageToken string, ctx
 }
 
 // Synchronous wrapper for ListAllAsync
-func (s HttpMediaItemsService) ListAll(options *ListOptions, ctx context.Context) ([]MediaItem, error) {
-	itemsC, errorsC := s.ListAllAsync(options, ctx)
+func (s HttpMediaItemsService) ListAll(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItem, error) {
+	itemsC, errorsC := s.ListAllAsync(options, ctx, opts...)
 	result := make([]MediaItem, 0)
 	for {
 		select {
@@ -220,7 +312,7 @@ func (s HttpMediaItemsService) ListAll(options *ListOptions, ctx context.Context
 }
 
 // Asynchronous wrapper for List that takes care of pagination. Returned channel has buffer size of 50
-func (s HttpMediaItemsService) ListAllAsync(options *ListOptions, ctx context.Context) (<-chan MediaItem, <-chan error) {
+func (s HttpMediaItemsService) ListAllAsync(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItem, <-chan error) {
 	itemsC := make(chan MediaItem, 50)
 	errorsC := make(chan error)
 	pageToken := ""
@@ -232,7 +324,7 @@ func (s HttpMediaItemsService) ListAllAsync(options *ListOptions, ctx context.Co
 				return
 			default:
 			}
-			items, token, err := s.List(options, pageToken, ctx)
+			items, token, err := s.List(options, pageToken, ctx, opts...)
 			if err != nil {
 				errorsC <- err
 				return
@@ -256,7 +348,7 @@ func (s HttpMediaItemsService) ListAllAsync(options *ListOptions, ctx context.Co
 // Fetches all media items based on search criteria. Default page size is 50
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/mediaItems/search
-func (s HttpMediaItemsService) Search(options *SearchOptions, pageToken string, ctx context.Context) (mediaItems []MediaItem, nextPageToken string, err error) {
+func (s HttpMediaItemsService) Search(options *SearchOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (mediaItems []MediaItem, nextPageToken string, err error) {
 	requestOptions := SearchOptions{
 		PageSize: 50,
 	}
@@ -271,7 +363,7 @@ func (s HttpMediaItemsService) Search(options *SearchOptions, pageToken string,
 		requestOptions,
 		pageToken,
 	}
-	err = s.c.PostJSON(s.path+":search", nil, optionsWithToken, responseModel, nil, ctx)
+	err = s.c.SearchMediaItems(optionsWithToken, responseModel, ctx, internal.RequireFields(opts, "nextPageToken")...)
 	if err != nil {
 		return nil, "", fmt.Errorf("cannot complete request: %w", err)
 	}
@@ -279,8 +371,8 @@ func (s HttpMediaItemsService) Search(options *SearchOptions, pageToken string,
 }
 
 // Synchronous wrapper for SearchAllAsync
-func (s HttpMediaItemsService) SearchAll(options *SearchOptions, ctx context.Context) ([]MediaItem, error) {
-	itemsC, errorsC := s.SearchAllAsync(options, ctx)
+func (s HttpMediaItemsService) SearchAll(options *SearchOptions, ctx context.Context, opts ...call_options.CallOption) ([]MediaItem, error) {
+	itemsC, errorsC := s.SearchAllAsync(options, ctx, opts...)
 	result := make([]MediaItem, 0)
 	for {
 		select {
@@ -296,7 +388,7 @@ func (s HttpMediaItemsService) SearchAll(options *SearchOptions, ctx context.Con
 }
 
 // Asynchronous wrapper for Search that takes care of pagination. Returned channel has buffer size of 50
-func (s HttpMediaItemsService) SearchAllAsync(options *SearchOptions, ctx context.Context) (<-chan MediaItem, <-chan error) {
+func (s HttpMediaItemsService) SearchAllAsync(options *SearchOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan MediaItem, <-chan error) {
 	itemsC := make(chan MediaItem, 50)
 	errorsC := make(chan error)
 	pageToken := ""
@@ -308,7 +400,7 @@ func (s HttpMediaItemsService) SearchAllAsync(options *SearchOptions, ctx contex
 				return
 			default:
 			}
-			items, token, err := s.Search(options, pageToken, ctx)
+			items, token, err := s.Search(options, pageToken, ctx, opts...)
 
 			if err != nil {
 				errorsC <- fmt.Errorf("cannot perform search: %w", err)
@@ -330,9 +422,9 @@ func (s HttpMediaItemsService) SearchAllAsync(options *SearchOptions, ctx contex
 	return itemsC, errorsC
 }
 
-func NewHttpMediaItemsService(httpClient *http.Client, uploader uploader.MediaUploader) HttpMediaItemsService {
+func NewHttpMediaItemsService(httpClient *http.Client, uploader uploader.MediaUploader, options ...client_options.ClientOption) HttpMediaItemsService {
 	return HttpMediaItemsService{
-		c:    internal.NewHttpClient(httpClient),
+		c:    internal.NewHttpClient(httpClient, options...),
 		u:    uploader,
 		path: "v1/mediaItems",
 	}
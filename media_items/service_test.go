@@ -0,0 +1,184 @@
+package media_items
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/uploader"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testIds(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = "item-" + strconv.Itoa(i)
+	}
+	return ids
+}
+
+// Returns media item for every requested id. delay is called before response is written
+func batchGetHandler(t *testing.T, requests *int32, delay func(ids []string)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/mediaItems:batchGet" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		ids := r.URL.Query()["mediaItemIds"]
+		if len(ids) == 0 || len(ids) > 50 {
+			t.Errorf("unexpected chunk size %d", len(ids))
+		}
+		atomic.AddInt32(requests, 1)
+		if delay != nil {
+			delay(ids)
+		}
+		response := batchGetMediaItemsResponse{}
+		for _, id := range ids {
+			response.MediaItemResults = append(response.MediaItemResults, MediaItemWithStatus{
+				MediaItem: MediaItem{ID: id},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
+
+func TestBatchGetItemsAll(t *testing.T) {
+	tests := []struct {
+		name             string
+		ids              int
+		options          BatchGetOptions
+		expectedRequests int32
+	}{
+		{"no ids", 0, BatchGetOptions{}, 0},
+		{"single chunk", 50, BatchGetOptions{}, 1},
+		{"chunk boundary", 51, BatchGetOptions{}, 2},
+		{"many chunks", 500, BatchGetOptions{}, 10},
+		{"many chunks in parallel", 500, BatchGetOptions{Concurrency: 4, PreserveOrder: true}, 10},
+		{"duplicated ids", 100, BatchGetOptions{Deduplicate: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := int32(0)
+			// later chunks are answered faster so parallel workers finish out of order
+			delay := func(ids []string) {
+				index, _ := strconv.Atoi(ids[0][len("item-"):])
+				time.Sleep(time.Duration(500-index) * 10 * time.Microsecond)
+			}
+			s := NewHttpMediaItemsService(test_utils.NewClient(t, batchGetHandler(t, &requests, delay)), nil)
+			ids := testIds(tt.ids)
+			expected := ids
+			if tt.options.Deduplicate {
+				ids = append(ids[:tt.ids/2:tt.ids/2], ids[:tt.ids/2]...)
+				expected = ids[:tt.ids/2]
+			}
+			items, err := s.BatchGetItemsAll(ids, &tt.options, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, got)
+			}
+			if len(items) != len(expected) {
+				t.Fatalf("expected %d items, got %d", len(expected), len(items))
+			}
+			for i := range items {
+				if items[i].MediaItem.ID != expected[i] {
+					t.Fatalf("item %d: expected %s, got %s", i, expected[i], items[i].MediaItem.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchGetItemsAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := int32(0)
+	once := sync.Once{}
+	s := NewHttpMediaItemsService(test_utils.NewClient(t, batchGetHandler(t, &requests, func([]string) {
+		once.Do(cancel)
+	})), nil)
+	items, err := s.BatchGetItemsAll(testIds(500), &BatchGetOptions{Concurrency: 2}, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancelled error, got %v (%d items)", err, len(items))
+	}
+	if got := atomic.LoadInt32(&requests); got >= 10 {
+		t.Errorf("expected remaining chunks to be skipped, got %d requests", got)
+	}
+}
+
+func TestBatchGetItemsAllResponseMeta(t *testing.T) {
+	requests := int32(0)
+	s := NewHttpMediaItemsService(test_utils.NewClient(t, batchGetHandler(t, &requests, nil)), nil)
+	meta := call_options.ResponseMeta{}
+	items, err := s.BatchGetItemsAll(testIds(500), &BatchGetOptions{
+		Concurrency: 4,
+	}, context.Background(), call_options.WithResponseMeta(&meta))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 500 {
+		t.Fatalf("expected 500 items, got %d", len(items))
+	}
+	if meta.StatusCode != http.StatusOK || meta.Path != "/v1/mediaItems:batchGet" {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestBatchCreateItemsFromFilesPassesCallOptions(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "photo.jpg")
+	err := ioutil.WriteFile(filePath, []byte("\xff\xd8\xff\xe0 not really a photo"), 0600)
+	if err != nil {
+		t.Fatalf("cannot write file: %v", err)
+	}
+	m := sync.Mutex{}
+	requests := make(map[string]string)
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		requests[r.URL.Path] = r.Header.Get("X-Test") + " " + r.URL.Query().Get("fields")
+		m.Unlock()
+		switch r.URL.Path {
+		case "/v1/uploads":
+			if r.Header.Get("X-Goog-Upload-File-Name") != "photo.jpg" {
+				t.Errorf("unexpected file name %q", r.Header.Get("X-Goog-Upload-File-Name"))
+			}
+			_, _ = w.Write([]byte("upload-token"))
+		case "/v1/mediaItems:batchCreate":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"newMediaItemResults": []map[string]interface{}{{"uploadToken": "upload-token", "mediaItem": map[string]string{"id": "item"}}},
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	s := NewHttpMediaItemsService(client, uploader.NewHttpMediaUploader(client))
+	result, err := s.BatchCreateItemsFromFiles("", []string{filePath}, albums.AlbumPosition{}, context.Background(),
+		call_options.WithHeader("X-Test", "value"), call_options.WithFields("newMediaItemResults(mediaItem(id))"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].MediaItem.ID != "item" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	expected := map[string]string{
+		// fields selector doesn't apply to upload response
+		"/v1/uploads":                "value ",
+		"/v1/mediaItems:batchCreate": "value newMediaItemResults(mediaItem(id))",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected %v, got %v", expected, requests)
+	}
+}
@@ -0,0 +1,74 @@
+package membership_index
+
+import (
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"sort"
+	"time"
+)
+
+type AlbumEntry struct {
+	Title string `json:"title"`
+	// Media items count reported by API when album was indexed. Used to detect changed albums
+	MediaItemsCount int64     `json:"mediaItemsCount,string"`
+	MediaItemIds    []string  `json:"mediaItemIds"`
+	RefreshedAt     time.Time `json:"refreshedAt"`
+}
+
+// Map of media items to albums containing them
+type Index struct {
+	Albums map[string]AlbumEntry `json:"albums"`
+	// media item id -> album ids. Rebuilt from Albums, not persisted
+	items map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Albums: make(map[string]AlbumEntry),
+		items:  make(map[string][]string),
+	}
+}
+
+// Loads index from file. Missing file results in empty index
+func Load(path string) (*Index, error) {
+	result := NewIndex()
+	err := internal.ReadJSONFile(path, result)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load index: %w", err)
+	}
+	if result.Albums == nil {
+		result.Albums = make(map[string]AlbumEntry)
+	}
+	result.rebuild()
+	return result, nil
+}
+
+func (i *Index) Save(path string) error {
+	err := internal.WriteJSONFile(path, i)
+	if err != nil {
+		return fmt.Errorf("cannot save index: %w", err)
+	}
+	return nil
+}
+
+func (i *Index) rebuild() {
+	i.items = make(map[string][]string)
+	for albumId, entry := range i.Albums {
+		for _, mediaItemId := range entry.MediaItemIds {
+			i.items[mediaItemId] = append(i.items[mediaItemId], albumId)
+		}
+	}
+	for _, albumIds := range i.items {
+		sort.Strings(albumIds)
+	}
+}
+
+// Returns IDs of albums containing media item
+func (i *Index) AlbumsOf(mediaItemId string) []string {
+	return i.items[mediaItemId]
+}
+
+// Returns true when media item is in at least one indexed album
+func (i *Index) Contains(mediaItemId string) bool {
+	return len(i.items[mediaItemId]) > 0
+}
@@ -0,0 +1,135 @@
+package membership_index
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"time"
+)
+
+type RefreshOptions struct {
+	// Reindex all albums. By default only new albums and albums with changed title or media items count are indexed.
+	// Incremental refresh won't notice albums where the same number of items was added and removed
+	Full bool
+}
+
+type RefreshResult struct {
+	// IDs of albums that were (re)indexed
+	Refreshed []string
+	// IDs of albums that no longer exist and were removed from index
+	Removed []string
+	// Number of albums skipped by incremental refresh
+	Unchanged int
+}
+
+// Builds index of album membership by walking all albums and searching their items
+type Indexer struct {
+	albums     albums.AlbumsService
+	mediaItems media_items.MediaItemsService
+}
+
+// Updates index with current album membership. API doesn't report when album contents changed, so incremental
+// refresh compares title and media items count with values stored in index
+func (x Indexer) Refresh(index *Index, options *RefreshOptions, ctx context.Context) (*RefreshResult, error) {
+	// stops album listing when refresh returns early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	refreshOptions := RefreshOptions{}
+	if options != nil {
+		refreshOptions = *options
+	}
+	result := &RefreshResult{
+		Refreshed: make([]string, 0),
+		Removed:   make([]string, 0),
+	}
+	// index is rebuilt even when refresh fails so it reflects albums refreshed so far
+	defer index.rebuild()
+	seen := make(map[string]struct{})
+	albumsC, errorsC := x.albums.ListAllAsync(nil, ctx)
+	for {
+		var album albums.Album
+		var ok bool
+		select {
+		case album, ok = <-albumsC:
+		case err := <-errorsC:
+			return result, fmt.Errorf("cannot list albums: %w", err)
+		}
+		if !ok {
+			break
+		}
+		seen[album.ID] = struct{}{}
+		entry, exists := index.Albums[album.ID]
+		// album with the same number of items added and removed looks unchanged, only full refresh picks it up
+		if exists && !refreshOptions.Full && entry.Title == album.Title && entry.MediaItemsCount == album.MediaItemsCount {
+			result.Unchanged++
+			continue
+		}
+		ids, err := x.albumItemIds(album.ID, ctx)
+		if err != nil {
+			return result, err
+		}
+		index.Albums[album.ID] = AlbumEntry{
+			Title:           album.Title,
+			MediaItemsCount: album.MediaItemsCount,
+			MediaItemIds:    ids,
+			RefreshedAt:     time.Now(),
+		}
+		result.Refreshed = append(result.Refreshed, album.ID)
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	for albumId := range index.Albums {
+		if _, ok := seen[albumId]; !ok {
+			delete(index.Albums, albumId)
+			result.Removed = append(result.Removed, albumId)
+		}
+	}
+	return result, nil
+}
+
+func (x Indexer) albumItemIds(albumId string, ctx context.Context) ([]string, error) {
+	itemsC, errorsC := x.mediaItems.SearchAllAsync(&media_items.SearchOptions{
+		PageSize: 100,
+		AlbumId:  albumId,
+	}, ctx)
+	result := make([]string, 0)
+	for {
+		select {
+		case item, ok := <-itemsC:
+			if !ok {
+				return result, ctx.Err()
+			}
+			result = append(result, item.ID)
+		case err := <-errorsC:
+			return nil, fmt.Errorf("cannot list items of album '%s': %w", albumId, err)
+		}
+	}
+}
+
+// Lists all media items in library that are not in any indexed album
+func (x Indexer) Orphans(index *Index, ctx context.Context) ([]media_items.MediaItem, error) {
+	itemsC, errorsC := x.mediaItems.ListAllAsync(&media_items.ListOptions{PageSize: 100}, ctx)
+	result := make([]media_items.MediaItem, 0)
+	for {
+		select {
+		case item, ok := <-itemsC:
+			if !ok {
+				return result, ctx.Err()
+			}
+			if !index.Contains(item.ID) {
+				result = append(result, item)
+			}
+		case err := <-errorsC:
+			return nil, fmt.Errorf("cannot list media items: %w", err)
+		}
+	}
+}
+
+func NewIndexer(albumsService albums.AlbumsService, mediaItemsService media_items.MediaItemsService) Indexer {
+	return Indexer{
+		albums:     albumsService,
+		mediaItems: mediaItemsService,
+	}
+}
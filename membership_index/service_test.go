@@ -0,0 +1,162 @@
+package membership_index
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func newTestIndexer(t *testing.T) (Indexer, *test_utils.Library) {
+	library := test_utils.NewLibrary(t)
+	library.AddMediaItems(
+		test_utils.LibraryMediaItem{ID: "a"},
+		test_utils.LibraryMediaItem{ID: "b"},
+		test_utils.LibraryMediaItem{ID: "c"},
+		test_utils.LibraryMediaItem{ID: "orphan"},
+	)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "trip", Title: "Trip", MediaItemIds: []string{"a", "b"}},
+		test_utils.LibraryAlbum{ID: "best", Title: "Best", MediaItemIds: []string{"b", "c"}},
+	)
+	client := test_utils.NewClient(t, library)
+	return NewIndexer(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil)), library
+}
+
+func TestIndexerRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// changes library after first refresh
+		change            func(library *test_utils.Library)
+		full              bool
+		expectedRefreshed []string
+		expectedRemoved   []string
+		expectedAlbumsOfB []string
+	}{
+		{"unchanged library", func(*test_utils.Library) {}, false, []string{}, []string{}, []string{"best", "trip"}},
+		{"full refresh", func(*test_utils.Library) {}, true, []string{"trip", "best"}, []string{}, []string{"best", "trip"}},
+		{"renamed album", func(library *test_utils.Library) {
+			library.RemoveAlbum("trip")
+			library.AddAlbums(test_utils.LibraryAlbum{ID: "trip", Title: "Trip 2020", MediaItemIds: []string{"a", "b"}})
+		}, false, []string{"trip"}, []string{}, []string{"best", "trip"}},
+		{"item removed", func(library *test_utils.Library) {
+			library.RemoveAlbum("trip")
+			library.AddAlbums(test_utils.LibraryAlbum{ID: "trip", Title: "Trip", MediaItemIds: []string{"a"}})
+		}, false, []string{"trip"}, []string{}, []string{"best"}},
+		// count stays the same so incremental refresh doesn't notice the change
+		{"item replaced", func(library *test_utils.Library) {
+			library.RemoveAlbum("trip")
+			library.AddAlbums(test_utils.LibraryAlbum{ID: "trip", Title: "Trip", MediaItemIds: []string{"a", "c"}})
+		}, false, []string{}, []string{}, []string{"best", "trip"}},
+		{"item replaced with full refresh", func(library *test_utils.Library) {
+			library.RemoveAlbum("trip")
+			library.AddAlbums(test_utils.LibraryAlbum{ID: "trip", Title: "Trip", MediaItemIds: []string{"a", "c"}})
+		}, true, []string{"best", "trip"}, []string{}, []string{"best"}},
+		{"album removed", func(library *test_utils.Library) {
+			library.RemoveAlbum("best")
+		}, false, []string{}, []string{"best"}, []string{"trip"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer, library := newTestIndexer(t)
+			index := NewIndex()
+			result, err := indexer.Refresh(index, nil, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Refreshed, []string{"trip", "best"}) {
+				t.Fatalf("expected all albums to be indexed, got %v", result.Refreshed)
+			}
+			tt.change(library)
+			searches := library.CountRequests("POST", "/v1/mediaItems:search")
+			result, err = indexer.Refresh(index, &RefreshOptions{Full: tt.full}, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result.Refreshed, tt.expectedRefreshed) {
+				t.Errorf("expected %v to be refreshed, got %v", tt.expectedRefreshed, result.Refreshed)
+			}
+			if got := library.CountRequests("POST", "/v1/mediaItems:search") - searches; got != len(tt.expectedRefreshed) {
+				t.Errorf("expected %d album searches, got %d", len(tt.expectedRefreshed), got)
+			}
+			if !reflect.DeepEqual(result.Removed, tt.expectedRemoved) {
+				t.Errorf("expected %v to be removed, got %v", tt.expectedRemoved, result.Removed)
+			}
+			if result.Unchanged+len(result.Refreshed)+len(result.Removed) != 2 {
+				t.Errorf("expected every album to be counted once, got %+v", result)
+			}
+			if got := index.AlbumsOf("b"); !reflect.DeepEqual(got, tt.expectedAlbumsOfB) {
+				t.Errorf("expected item to be in %v, got %v", tt.expectedAlbumsOfB, got)
+			}
+		})
+	}
+}
+
+func TestIndexerRefreshFailure(t *testing.T) {
+	indexer, library := newTestIndexer(t)
+	library.Fail("GET", "/v1/albums", 500, "INTERNAL")
+	index := NewIndex()
+	index.Albums["old"] = AlbumEntry{Title: "Old", MediaItemIds: []string{"a"}}
+	_, err := indexer.Refresh(index, nil, context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if _, ok := index.Albums["old"]; !ok {
+		t.Error("albums shouldn't be removed from index when listing fails")
+	}
+}
+
+func TestIndexerOrphans(t *testing.T) {
+	indexer, _ := newTestIndexer(t)
+	index := NewIndex()
+	_, err := indexer.Refresh(index, nil, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	orphans, err := indexer.Orphans(index, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].ID != "orphan" {
+		t.Errorf("expected only orphan item, got %v", orphans)
+	}
+}
+
+func TestIndexSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	missing, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(missing.Albums) != 0 || missing.Contains("a") {
+		t.Error("expected empty index when file doesn't exist")
+	}
+	indexer, _ := newTestIndexer(t)
+	index := NewIndex()
+	_, err = indexer.Refresh(index, nil, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = index.Save(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Albums["trip"].MediaItemsCount != 2 || loaded.Albums["trip"].Title != "Trip" {
+		t.Errorf("album entry wasn't persisted: %+v", loaded.Albums["trip"])
+	}
+	for _, id := range []string{"a", "b", "c", "orphan"} {
+		got, expected := loaded.AlbumsOf(id), index.AlbumsOf(id)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v after load, got %v", id, expected, got)
+		}
+	}
+}
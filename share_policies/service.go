@@ -0,0 +1,132 @@
+package share_policies
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"regexp"
+	"time"
+)
+
+// Rules deciding which shared albums should be unshared
+type Policy struct {
+	// Albums shared longer than TTL are unshared. Zero disables the rule
+	TTL time.Duration
+	// Albums with titles not matching any of patterns are unshared. Empty list disables the rule
+	AllowedTitlePatterns []*regexp.Regexp
+}
+
+type EnforceOptions struct {
+	// Only report albums that would be unshared
+	DryRun bool
+}
+
+// Policy result for single shared album
+type Decision struct {
+	AlbumId  string
+	Title    string
+	SharedAt time.Time
+	// Empty when album doesn't violate policy
+	Reason string
+	// Album was unshared (always false in dry run mode)
+	Unshared bool
+	Err      error
+}
+
+// Unshares owned albums that violate policy
+type Engine struct {
+	albums albums.AlbumsService
+	store  Store
+	policy Policy
+}
+
+// Shares album and records share time
+func (e Engine) Share(albumId string, options albums.SharedAlbumOptions, ctx context.Context) (*albums.AlbumShareInfo, error) {
+	shareInfo, err := e.albums.Share(albumId, options, ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = e.store.RecordShared(albumId, time.Now())
+	if err != nil {
+		return shareInfo, fmt.Errorf("album shared but share time wasn't recorded: %w", err)
+	}
+	return shareInfo, nil
+}
+
+func (e Engine) violation(album albums.Album, sharedAt time.Time, now time.Time) string {
+	if e.policy.TTL > 0 && now.Sub(sharedAt) > e.policy.TTL {
+		return fmt.Sprintf("shared for longer than %s", e.policy.TTL)
+	}
+	if len(e.policy.AllowedTitlePatterns) == 0 {
+		return ""
+	}
+	for _, pattern := range e.policy.AllowedTitlePatterns {
+		if pattern.MatchString(album.Title) {
+			return ""
+		}
+	}
+	return "title doesn't match allowed patterns"
+}
+
+// Checks all owned shared albums against policy and unshares violating ones. Albums shared outside of Engine.Share
+// are recorded with the time they were first seen. Returns decisions for all owned shared albums
+func (e Engine) Enforce(options *EnforceOptions, ctx context.Context) ([]Decision, error) {
+	enforceOptions := EnforceOptions{}
+	if options != nil {
+		enforceOptions = *options
+	}
+	allAlbums, err := e.albums.ListAll(nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list albums: %w", err)
+	}
+	now := time.Now()
+	result := make([]Decision, 0)
+	failed := 0
+	for _, album := range allAlbums {
+		if !album.IsShared() || !album.ShareInfo.IsOwned {
+			continue
+		}
+		sharedAt, err := e.store.SharedAt(album.ID)
+		if err != nil {
+			return result, fmt.Errorf("cannot get share time: %w", err)
+		}
+		if sharedAt.IsZero() {
+			sharedAt = now
+			if !enforceOptions.DryRun {
+				err = e.store.RecordShared(album.ID, sharedAt)
+				if err != nil {
+					return result, fmt.Errorf("cannot record share time: %w", err)
+				}
+			}
+		}
+		decision := Decision{
+			AlbumId:  album.ID,
+			Title:    album.Title,
+			SharedAt: sharedAt,
+			Reason:   e.violation(album, sharedAt, now),
+		}
+		if decision.Reason != "" && !enforceOptions.DryRun {
+			decision.Err = e.albums.Unshare(album.ID, ctx)
+			if decision.Err == nil {
+				decision.Unshared = true
+				decision.Err = e.store.Forget(album.ID)
+			}
+			if decision.Err != nil {
+				failed++
+			}
+		}
+		result = append(result, decision)
+	}
+	if failed > 0 {
+		return result, fmt.Errorf("cannot unshare %d albums", failed)
+	}
+	return result, nil
+}
+
+func NewEngine(albumsService albums.AlbumsService, store Store, policy Policy) Engine {
+	return Engine{
+		albums: albumsService,
+		store:  store,
+		policy: policy,
+	}
+}
@@ -0,0 +1,140 @@
+package share_policies
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func owned() map[string]interface{} {
+	return map[string]interface{}{"isOwned": true, "shareToken": "token"}
+}
+
+func newTestEngine(t *testing.T, policy Policy) (Engine, *test_utils.Library, FileStore) {
+	library := test_utils.NewLibrary(t)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "old", Title: "Family 2019", ShareInfo: owned()},
+		test_utils.LibraryAlbum{ID: "recent", Title: "Family 2020", ShareInfo: owned()},
+		test_utils.LibraryAlbum{ID: "party", Title: "Party", ShareInfo: owned()},
+		test_utils.LibraryAlbum{ID: "private", Title: "Private"},
+		test_utils.LibraryAlbum{ID: "joined", Title: "Joined", ShareInfo: map[string]interface{}{"isJoined": true}},
+	)
+	store := NewFileStore(filepath.Join(t.TempDir(), "share_times.json"))
+	_ = store.RecordShared("old", time.Now().Add(-48*time.Hour))
+	_ = store.RecordShared("recent", time.Now().Add(-time.Hour))
+	return NewEngine(albums.NewHttpAlbumsService(test_utils.NewClient(t, library)), store, policy), library, store
+}
+
+func TestEngineEnforce(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		dryRun bool
+		// album id -> reason
+		expectedViolations map[string]string
+	}{
+		{"no rules", Policy{}, false, map[string]string{}},
+		{"ttl", Policy{TTL: 24 * time.Hour}, false, map[string]string{"old": "shared for longer than 24h0m0s"}},
+		{"title patterns", Policy{AllowedTitlePatterns: []*regexp.Regexp{regexp.MustCompile(`^Family`)}}, false,
+			map[string]string{"party": "title doesn't match allowed patterns"}},
+		{"ttl and title patterns", Policy{TTL: 24 * time.Hour, AllowedTitlePatterns: []*regexp.Regexp{regexp.MustCompile(`^Family`)}}, false,
+			map[string]string{"old": "shared for longer than 24h0m0s", "party": "title doesn't match allowed patterns"}},
+		{"dry run", Policy{TTL: 24 * time.Hour}, true, map[string]string{"old": "shared for longer than 24h0m0s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, library, store := newTestEngine(t, tt.policy)
+			decisions, err := engine.Enforce(&EnforceOptions{DryRun: tt.dryRun}, context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ids := make([]string, 0)
+			for _, decision := range decisions {
+				ids = append(ids, decision.AlbumId)
+				if decision.Reason != tt.expectedViolations[decision.AlbumId] {
+					t.Errorf("%s: expected reason %q, got %q", decision.AlbumId, tt.expectedViolations[decision.AlbumId], decision.Reason)
+				}
+				unshared := decision.Reason != "" && !tt.dryRun
+				if decision.Unshared != unshared || decision.Err != nil {
+					t.Errorf("%s: expected unshared %v, got %+v", decision.AlbumId, unshared, decision)
+				}
+				if (library.Album(decision.AlbumId).ShareInfo == nil) != unshared {
+					t.Errorf("%s: unexpected share state in library", decision.AlbumId)
+				}
+				// dry run doesn't record albums seen for the first time
+				sharedAt, _ := store.SharedAt(decision.AlbumId)
+				if !tt.dryRun && sharedAt.IsZero() != unshared {
+					t.Errorf("%s: expected share time to be forgotten only for unshared album, got %v", decision.AlbumId, sharedAt)
+				}
+			}
+			if !reflect.DeepEqual(ids, []string{"old", "recent", "party"}) {
+				t.Errorf("expected decisions for owned shared albums, got %v", ids)
+			}
+			if tt.dryRun && len(library.Requests()) != 1 {
+				t.Errorf("dry run should only list albums, got %v", library.Requests())
+			}
+		})
+	}
+}
+
+func TestEngineEnforceRecordsFirstSeen(t *testing.T) {
+	engine, _, store := newTestEngine(t, Policy{TTL: time.Hour})
+	dryRunDecisions, err := engine.Enforce(&EnforceOptions{DryRun: true}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sharedAt, _ := store.SharedAt("party"); !sharedAt.IsZero() {
+		t.Error("dry run shouldn't record share time")
+	}
+	if dryRunDecisions[2].SharedAt.IsZero() || dryRunDecisions[2].Reason != "" {
+		t.Errorf("album without share time should be treated as shared now, got %+v", dryRunDecisions[2])
+	}
+	before := time.Now()
+	_, err = engine.Enforce(nil, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	firstSeen, _ := store.SharedAt("party")
+	if firstSeen.Before(before) || firstSeen.After(time.Now()) {
+		t.Errorf("expected first seen time to be recorded, got %v", firstSeen)
+	}
+	_, _ = engine.Enforce(nil, context.Background())
+	if sharedAt, _ := store.SharedAt("party"); !sharedAt.Equal(firstSeen) {
+		t.Errorf("first seen time shouldn't be overwritten, got %v", sharedAt)
+	}
+}
+
+func TestEngineShare(t *testing.T) {
+	engine, library, store := newTestEngine(t, Policy{})
+	before := time.Now()
+	shareInfo, err := engine.Share("private", albums.SharedAlbumOptions{IsCollaborative: true}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if shareInfo.ShareToken == "" || library.Album("private").ShareInfo == nil {
+		t.Error("expected album to be shared")
+	}
+	if sharedAt, _ := store.SharedAt("private"); sharedAt.Before(before) {
+		t.Errorf("expected share time to be recorded, got %v", sharedAt)
+	}
+}
+
+func TestEngineEnforceUnshareFailure(t *testing.T) {
+	engine, library, store := newTestEngine(t, Policy{TTL: 24 * time.Hour})
+	library.Fail("POST", "/v1/albums/old:unshare", 500, "INTERNAL")
+	decisions, err := engine.Enforce(nil, context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if decisions[0].Unshared || decisions[0].Err == nil {
+		t.Errorf("expected failed decision, got %+v", decisions[0])
+	}
+	if sharedAt, _ := store.SharedAt("old"); sharedAt.IsZero() {
+		t.Error("share time of album that wasn't unshared should be kept")
+	}
+}
@@ -0,0 +1,58 @@
+package share_policies
+
+import (
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"time"
+)
+
+// Remembers when albums were shared. API doesn't expose that information
+type Store interface {
+	// Returns zero time when album share time is unknown
+	SharedAt(albumId string) (time.Time, error)
+	RecordShared(albumId string, sharedAt time.Time) error
+	Forget(albumId string) error
+}
+
+// Store keeping share times in single JSON file
+type FileStore struct {
+	file internal.JSONFileStore
+}
+
+func (s FileStore) update(fn func(sharedAt map[string]time.Time)) error {
+	sharedAt := make(map[string]time.Time)
+	err := s.file.Update(&sharedAt, func() {
+		fn(sharedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save share times: %w", err)
+	}
+	return nil
+}
+
+func (s FileStore) SharedAt(albumId string) (time.Time, error) {
+	sharedAt := make(map[string]time.Time)
+	err := s.file.Load(&sharedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot load share times: %w", err)
+	}
+	return sharedAt[albumId], nil
+}
+
+func (s FileStore) RecordShared(albumId string, at time.Time) error {
+	return s.update(func(sharedAt map[string]time.Time) {
+		sharedAt[albumId] = at
+	})
+}
+
+func (s FileStore) Forget(albumId string) error {
+	return s.update(func(sharedAt map[string]time.Time) {
+		delete(sharedAt, albumId)
+	})
+}
+
+func NewFileStore(path string) FileStore {
+	return FileStore{
+		file: internal.NewJSONFileStore(path),
+	}
+}
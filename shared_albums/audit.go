@@ -0,0 +1,75 @@
+package shared_albums
+
+import (
+	"context"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"sort"
+)
+
+// Sharing state of single album
+type AuditEntry struct {
+	AlbumId string
+	Title   string
+	// Album is listed in Albums tab (albums.list)
+	InAlbums bool
+	// Album is listed in Sharing tab (sharedAlbums.list)
+	InSharedAlbums  bool
+	IsShared        bool
+	IsCollaborative bool
+	IsCommentable   bool
+	IsOwned         bool
+	IsJoined        bool
+	ShareableURL    string
+}
+
+// Lists sharing state of all albums from Albums.ListAll and SharedAlbums.ListAll. Albums present in both lists are
+// reported once. Entries are ordered by title
+func Audit(albumsService albums.AlbumsService, sharedAlbumsService SharedAlbumsService, ctx context.Context) ([]AuditEntry, error) {
+	libraryAlbums, err := albumsService.ListAll(nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list albums: %w", err)
+	}
+	sharedAlbums, err := sharedAlbumsService.ListAll(nil, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list shared albums: %w", err)
+	}
+	entries := make(map[string]*AuditEntry)
+	add := func(album albums.Album) *AuditEntry {
+		entry, ok := entries[album.ID]
+		if !ok {
+			entry = &AuditEntry{
+				AlbumId: album.ID,
+				Title:   album.Title,
+			}
+			entries[album.ID] = entry
+		}
+		if album.IsShared() {
+			shareInfo := album.ShareInfo
+			entry.IsShared = true
+			entry.IsCollaborative = shareInfo.SharedAlbumOptions.IsCollaborative
+			entry.IsCommentable = shareInfo.SharedAlbumOptions.IsCommentable
+			entry.IsOwned = shareInfo.IsOwned
+			entry.IsJoined = shareInfo.IsJoined
+			entry.ShareableURL = shareInfo.ShareableURL
+		}
+		return entry
+	}
+	for _, album := range libraryAlbums {
+		add(album).InAlbums = true
+	}
+	for _, album := range sharedAlbums {
+		add(album).InSharedAlbums = true
+	}
+	result := make([]AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Title != result[j].Title {
+			return result[i].Title < result[j].Title
+		}
+		return result[i].AlbumId < result[j].AlbumId
+	})
+	return result, nil
+}
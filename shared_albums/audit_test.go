@@ -0,0 +1,48 @@
+package shared_albums
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"reflect"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	library := test_utils.NewLibrary(t)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "private", Title: "Private"},
+		test_utils.LibraryAlbum{ID: "owned", Title: "Owned", ShareInfo: map[string]interface{}{
+			"sharedAlbumOptions": map[string]bool{"isCollaborative": true},
+			"shareableUrl":       "https://photos.app.goo.gl/owned",
+			"isOwned":            true,
+			"isJoined":           true,
+		}},
+		test_utils.LibraryAlbum{ID: "joined", Title: "Joined", SharedOnly: true, ShareInfo: map[string]interface{}{
+			"sharedAlbumOptions": map[string]bool{"isCommentable": true},
+			"isJoined":           true,
+		}},
+		// albums with the same title are ordered by id
+		test_utils.LibraryAlbum{ID: "another-private", Title: "Private"},
+	)
+	client := test_utils.NewClient(t, library)
+	entries, err := Audit(albums.NewHttpAlbumsService(client), NewHttpSharedAlbumsService(client), context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []AuditEntry{
+		{AlbumId: "joined", Title: "Joined", InSharedAlbums: true, IsShared: true, IsCommentable: true, IsJoined: true},
+		{AlbumId: "owned", Title: "Owned", InAlbums: true, InSharedAlbums: true, IsShared: true, IsCollaborative: true,
+			IsOwned: true, IsJoined: true, ShareableURL: "https://photos.app.goo.gl/owned"},
+		{AlbumId: "another-private", Title: "Private", InAlbums: true},
+		{AlbumId: "private", Title: "Private", InAlbums: true},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+	library.Fail("GET", "/v1/sharedAlbums", 500, "INTERNAL")
+	_, err = Audit(albums.NewHttpAlbumsService(client), NewHttpSharedAlbumsService(client), context.Background())
+	if err == nil {
+		t.Error("expected error when shared albums cannot be listed")
+	}
+}
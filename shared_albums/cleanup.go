@@ -0,0 +1,107 @@
+package shared_albums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"regexp"
+	"time"
+)
+
+// Criteria of stale albums. Album is stale when it matches all specified criteria
+type StaleAlbumsOptions struct {
+	// Leave stale albums. Without it cleanup only reports them
+	Leave bool
+	// Albums without media items taken after this time (checked with mediaItems.search). Media item creationTime is
+	// the time photo or video was taken - API doesn't tell when item was added to album so albums with recently
+	// added old photos are also stale
+	NoItemsTakenSince time.Time
+	// Albums without media items
+	Empty bool
+	// Albums with titles matching pattern
+	TitlePattern *regexp.Regexp
+}
+
+type StaleAlbum struct {
+	AlbumId         string
+	Title           string
+	ShareToken      string
+	MediaItemsCount int64
+	// Time when newest media item was taken (media item creationTime). Zero when album wasn't searched or is empty
+	LastItemTime time.Time
+	// Album was left (always false without StaleAlbumsOptions.Leave)
+	Left bool
+	Err  error
+}
+
+// Finds joined albums owned by other users that match stale criteria and leaves them when options.Leave is set.
+// Returns report of stale albums. Fields call option is ignored
+func (s HttpSharedAlbumsService) CleanupStale(options StaleAlbumsOptions, ctx context.Context, opts ...call_options.CallOption) ([]StaleAlbum, error) {
+	if options.NoItemsTakenSince.IsZero() && !options.Empty && options.TitlePattern == nil {
+		return nil, errors.New("at least one stale album criterion is required")
+	}
+	sharedAlbums, err := s.ListAll(nil, ctx, internal.WithoutFields(opts)...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list shared albums: %w", err)
+	}
+	result := make([]StaleAlbum, 0)
+	failed := 0
+	for _, album := range sharedAlbums {
+		if !album.IsShared() || !album.ShareInfo.IsJoined || album.IsOwnedByMe() {
+			continue
+		}
+		if options.TitlePattern != nil && !options.TitlePattern.MatchString(album.Title) {
+			continue
+		}
+		if options.Empty && album.MediaItemsCount > 0 {
+			continue
+		}
+		staleAlbum := StaleAlbum{
+			AlbumId:         album.ID,
+			Title:           album.Title,
+			ShareToken:      album.ShareInfo.ShareToken,
+			MediaItemsCount: album.MediaItemsCount,
+		}
+		if !options.NoItemsTakenSince.IsZero() {
+			staleAlbum.LastItemTime, err = s.lastItemTime(album.ID, ctx, opts...)
+			if err != nil {
+				return result, err
+			}
+			if !staleAlbum.LastItemTime.Before(options.NoItemsTakenSince) {
+				continue
+			}
+		}
+		if options.Leave {
+			staleAlbum.Err = s.Leave(staleAlbum.ShareToken, ctx, opts...)
+			staleAlbum.Left = staleAlbum.Err == nil
+			if staleAlbum.Err != nil {
+				failed++
+			}
+		}
+		result = append(result, staleAlbum)
+	}
+	if failed > 0 {
+		return result, fmt.Errorf("cannot leave %d of %d stale albums", failed, len(result))
+	}
+	return result, nil
+}
+
+func (s HttpSharedAlbumsService) lastItemTime(albumId string, ctx context.Context, opts ...call_options.CallOption) (time.Time, error) {
+	items, err := s.c.ListAlbumItems(albumId, ctx, opts...)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot list items of album '%s': %w", albumId, err)
+	}
+	result := time.Time{}
+	for _, item := range items {
+		creationTime, err := time.Parse(time.RFC3339, item.MediaMetadata.CreationTime)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid creation time of media item '%s': %w", item.ID, err)
+		}
+		if creationTime.After(result) {
+			result = creationTime
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,94 @@
+package shared_albums
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanupStale(t *testing.T) {
+	joined := func(id string) albums.Album {
+		return albums.Album{
+			ID:              id,
+			Title:           id,
+			MediaItemsCount: 1,
+			ShareInfo:       &albums.AlbumShareInfo{ShareToken: id + "-token", IsJoined: true},
+		}
+	}
+	owned := joined("owned")
+	owned.ShareInfo.IsOwned = true
+	// creationTime is capture time so recently added old photo doesn't make album active
+	itemTimes := map[string]string{
+		"owned":    "2019-01-01T10:00:00Z",
+		"old":      "2019-06-01T10:00:00Z",
+		"recent":   "2020-06-01T10:00:00Z",
+		"oldAdded": "2019-12-31T10:00:00Z",
+	}
+	m := sync.Mutex{}
+	left := make([]string, 0)
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/sharedAlbums":
+			_ = json.NewEncoder(w).Encode(multipleAlbumsResponse{
+				SharedAlbums: []albums.Album{owned, joined("old"), joined("recent"), joined("oldAdded")},
+			})
+		case "/v1/mediaItems:search":
+			body := struct {
+				AlbumId string `json:"albumId"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"mediaItems": []map[string]interface{}{{
+					"id":            body.AlbumId + "-item",
+					"mediaMetadata": map[string]string{"creationTime": itemTimes[body.AlbumId]},
+				}},
+			})
+		case "/v1/sharedAlbums:leave":
+			body := struct {
+				ShareToken string `json:"shareToken"`
+			}{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			m.Lock()
+			left = append(left, body.ShareToken)
+			m.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	result, err := NewHttpSharedAlbumsService(client).CleanupStale(StaleAlbumsOptions{
+		Leave:             true,
+		NoItemsTakenSince: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	staleIds := make([]string, 0)
+	for _, album := range result {
+		staleIds = append(staleIds, album.AlbumId)
+		if !album.Left || album.LastItemTime.Format(time.RFC3339) != itemTimes[album.AlbumId] {
+			t.Errorf("unexpected stale album %+v", album)
+		}
+	}
+	if expected := []string{"old", "oldAdded"}; !reflect.DeepEqual(staleIds, expected) {
+		t.Errorf("expected stale albums %v, got %v", expected, staleIds)
+	}
+	if expected := []string{"old-token", "oldAdded-token"}; !reflect.DeepEqual(left, expected) {
+		t.Errorf("expected to leave %v, got %v", expected, left)
+	}
+}
+
+func TestCleanupStaleRequiresCriterion(t *testing.T) {
+	_, err := NewHttpSharedAlbumsService(http.DefaultClient).CleanupStale(StaleAlbumsOptions{Leave: true}, context.Background())
+	if err == nil {
+		t.Error("expected error")
+	}
+}
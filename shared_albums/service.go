@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
 	"github.com/duffpl/google-photos-api-client/internal"
 	"github.com/imdario/mergo"
 	"net/http"
@@ -11,12 +13,14 @@ import (
 
 // Interface for https://developers.google.com/photos/library/reference/rest/v1/sharedAlbums resource
 type SharedAlbumsService interface {
-	Get(shareToken string, ctx context.Context) (*albums.Album, error)
-	Join(shareToken string, ctx context.Context) (*albums.Album, error)
-	Leave(shareToken string, ctx context.Context) error
-	List(options *ListOptions, pageToken string, ctx context.Context) (result []albums.Album, nextPageToken string, err error)
-	ListAll(options *ListOptions, ctx context.Context) ([]albums.Album, error)
-	ListAllAsync(options *ListOptions, ctx context.Context) (<-chan albums.Album, <-chan error)
+	CleanupStale(options StaleAlbumsOptions, ctx context.Context, opts ...call_options.CallOption) ([]StaleAlbum, error)
+	Get(shareToken string, ctx context.Context, opts ...call_options.CallOption) (*albums.Album, error)
+	Join(shareToken string, ctx context.Context, opts ...call_options.CallOption) (*albums.Album, error)
+	JoinAll(shareUrls []string, resolver URLResolver, ctx context.Context, opts ...call_options.CallOption) ([]JoinResult, error)
+	Leave(shareToken string, ctx context.Context, opts ...call_options.CallOption) error
+	List(options *ListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (result []albums.Album, nextPageToken string, err error)
+	ListAll(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) ([]albums.Album, error)
+	ListAllAsync(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan albums.Album, <-chan error)
 }
 
 type HttpSharedAlbumsService struct {
@@ -27,9 +31,9 @@ type HttpSharedAlbumsService struct {
 // Fetches album based on specified shareToken
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/sharedAlbums/get
-func (s HttpSharedAlbumsService) Get(shareToken string, ctx context.Context) (*albums.Album, error) {
+func (s HttpSharedAlbumsService) Get(shareToken string, ctx context.Context, opts ...call_options.CallOption) (*albums.Album, error) {
 	responseModel := &albums.Album{}
-	err := s.c.FetchWithGet(s.path+"/"+shareToken, nil, responseModel, nil, ctx)
+	err := s.c.FetchWithGet(s.path+"/"+shareToken, nil, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch shared album: %w", err)
 	}
@@ -40,26 +44,50 @@ func (s HttpSharedAlbumsService) Get(shareToken string, ctx context.Context) (*a
 // Joins a shared album on behalf of the Google Photos user.
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/sharedAlbums/join
-func (s HttpSharedAlbumsService) Join(shareToken string, ctx context.Context) (*albums.Album, error) {
+func (s HttpSharedAlbumsService) Join(shareToken string, ctx context.Context, opts ...call_options.CallOption) (*albums.Album, error) {
 	responseModel := &singleAlbumResponse{}
 	body := shareTokenBody{
 		ShareToken: shareToken,
 	}
-	err := s.c.PostJSON(s.path+":join", nil, body, responseModel, nil, ctx)
+	err := s.c.PostJSON(s.path+":join", nil, body, responseModel, nil, ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("cannot join shared album: %w", err)
 	}
 	return &responseModel.Album, nil
 }
 
+// Joins multiple shared albums specified by shareable URLs or share tokens (see ParseShareToken). Failure of single
+// album doesn't stop joining others. Returned error is non-nil when any album failed
+func (s HttpSharedAlbumsService) JoinAll(shareUrls []string, resolver URLResolver, ctx context.Context, opts ...call_options.CallOption) ([]JoinResult, error) {
+	result := make([]JoinResult, 0, len(shareUrls))
+	failed := 0
+	for _, shareUrl := range shareUrls {
+		joinResult := JoinResult{
+			ShareUrl: shareUrl,
+		}
+		joinResult.ShareToken, joinResult.Err = ParseShareToken(shareUrl, resolver, ctx)
+		if joinResult.Err == nil {
+			joinResult.Album, joinResult.Err = s.Join(joinResult.ShareToken, ctx, opts...)
+		}
+		if joinResult.Err != nil {
+			failed++
+		}
+		result = append(result, joinResult)
+	}
+	if failed > 0 {
+		return result, fmt.Errorf("cannot join %d of %d shared albums", failed, len(shareUrls))
+	}
+	return result, nil
+}
+
 // Leaves a previously-joined shared album on behalf of the Google Photos user. The user must not own this album.
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/sharedAlbums/leave
-func (s HttpSharedAlbumsService) Leave(shareToken string, ctx context.Context) error {
+func (s HttpSharedAlbumsService) Leave(shareToken string, ctx context.Context, opts ...call_options.CallOption) error {
 	body := shareTokenBody{
 		ShareToken: shareToken,
 	}
-	err := s.c.PostJSON(s.path+":leave", nil, body, nil, nil, ctx)
+	err := s.c.PostJSON(s.path+":leave", nil, body, nil, nil, ctx, opts...)
 	if err != nil {
 		return fmt.Errorf("cannot leave shared album: %w", err)
 	}
@@ -69,7 +97,7 @@ func (s HttpSharedAlbumsService) Leave(shareToken string, ctx context.Context) e
 // Lists all shared albums available in the Sharing tab of the user's Google Photos app.
 //
 // Doc: https://developers.google.com/photos/library/reference/rest/v1/sharedAlbums/list
-func (s HttpSharedAlbumsService) List(options *ListOptions, pageToken string, ctx context.Context) (result []albums.Album, nextPageToken string, err error) {
+func (s HttpSharedAlbumsService) List(options *ListOptions, pageToken string, ctx context.Context, opts ...call_options.CallOption) (result []albums.Album, nextPageToken string, err error) {
 	requestOptions := ListOptions{
 		PageSize: 50,
 	}
@@ -84,7 +112,7 @@ func (s HttpSharedAlbumsService) List(options *ListOptions, pageToken string, ct
 		pageToken,
 	}
 	responseModel := &multipleAlbumsResponse{}
-	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx)
+	err = s.c.FetchWithGet(s.path, optionsWithToken, responseModel, nil, ctx, internal.RequireFields(opts, "nextPageToken")...)
 	if err != nil {
 		return nil, "", fmt.Errorf("cannot list shared albums: %w", err)
 	}
@@ -92,8 +120,8 @@ func (s HttpSharedAlbumsService) List(options *ListOptions, pageToken string, ct
 }
 
 // Synchronous wrapper for ListAllAsync
-func (s HttpSharedAlbumsService) ListAll(options *ListOptions, ctx context.Context) ([]albums.Album, error) {
-	albumsC, errorsC := s.ListAllAsync(options, ctx)
+func (s HttpSharedAlbumsService) ListAll(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) ([]albums.Album, error) {
+	albumsC, errorsC := s.ListAllAsync(options, ctx, opts...)
 	result := make([]albums.Album, 0)
 	for {
 		select {
@@ -109,7 +137,7 @@ func (s HttpSharedAlbumsService) ListAll(options *ListOptions, ctx context.Conte
 }
 
 // Asynchronous wrapper for List that takes care of pagination. Returned channel has buffer size of 50
-func (s HttpSharedAlbumsService) ListAllAsync(options *ListOptions, ctx context.Context) (<-chan albums.Album, <-chan error) {
+func (s HttpSharedAlbumsService) ListAllAsync(options *ListOptions, ctx context.Context, opts ...call_options.CallOption) (<-chan albums.Album, <-chan error) {
 	albumsC := make(chan albums.Album, 50)
 	errorsC := make(chan error)
 	pageToken := ""
@@ -121,7 +149,7 @@ func (s HttpSharedAlbumsService) ListAllAsync(options *ListOptions, ctx context.
 				return
 			default:
 			}
-			items, token, err := s.List(options, pageToken, ctx)
+			items, token, err := s.List(options, pageToken, ctx, opts...)
 			if err != nil {
 				errorsC <- err
 				return
@@ -142,9 +170,9 @@ func (s HttpSharedAlbumsService) ListAllAsync(options *ListOptions, ctx context.
 	return albumsC, errorsC
 }
 
-func NewHttpSharedAlbumsService(authenticatedClient *http.Client) HttpSharedAlbumsService {
+func NewHttpSharedAlbumsService(authenticatedClient *http.Client, options ...client_options.ClientOption) HttpSharedAlbumsService {
 	return HttpSharedAlbumsService{
-		c:    internal.NewHttpClient(authenticatedClient),
+		c:    internal.NewHttpClient(authenticatedClient, options...),
 		path: "v1/sharedAlbums",
 	}
 }
@@ -0,0 +1,57 @@
+package shared_albums
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"strings"
+	"testing"
+)
+
+func TestJoinAll(t *testing.T) {
+	library := test_utils.NewLibrary(t)
+	library.AddAlbums(
+		test_utils.LibraryAlbum{ID: "trip", Title: "Trip", SharedOnly: true, ShareInfo: map[string]interface{}{"shareToken": "trip-token"}},
+		test_utils.LibraryAlbum{ID: "party", Title: "Party", SharedOnly: true, ShareInfo: map[string]interface{}{"shareToken": "party-token"}},
+	)
+	s := NewHttpSharedAlbumsService(test_utils.NewClient(t, library))
+	shareUrls := []string{
+		"https://photos.google.com/share/trip-token?key=browserKey",
+		"https://photos.google.com/albums",
+		"missing-token",
+		"party-token",
+	}
+	result, err := s.JoinAll(shareUrls, nil, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "2 of 4") {
+		t.Fatalf("expected 2 failures, got %v", err)
+	}
+	if len(result) != len(shareUrls) {
+		t.Fatalf("expected result for every url, got %d", len(result))
+	}
+	expected := []struct {
+		shareToken string
+		albumId    string
+	}{
+		{"trip-token", "trip"},
+		// invalid url isn't sent to API
+		{"", ""},
+		{"missing-token", ""},
+		{"party-token", "party"},
+	}
+	for i, joinResult := range result {
+		if joinResult.ShareUrl != shareUrls[i] || joinResult.ShareToken != expected[i].shareToken {
+			t.Errorf("%d: unexpected url or token %+v", i, joinResult)
+		}
+		if expected[i].albumId == "" {
+			if joinResult.Err == nil || joinResult.Album != nil {
+				t.Errorf("%d: expected failure, got %+v", i, joinResult)
+			}
+			continue
+		}
+		if joinResult.Err != nil || joinResult.Album == nil || joinResult.Album.ID != expected[i].albumId {
+			t.Errorf("%d: expected album %s, got %+v", i, expected[i].albumId, joinResult)
+		}
+	}
+	if got := library.CountRequests("POST", "/v1/sharedAlbums:join"); got != 3 {
+		t.Errorf("expected 3 join requests, got %d", got)
+	}
+}
@@ -0,0 +1,135 @@
+package shared_albums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resolves short shareable URLs (photos.app.goo.gl) into full URLs
+type URLResolver interface {
+	Resolve(shortUrl string, ctx context.Context) (string, error)
+}
+
+// Resolver following HTTP redirects until URL leaves goo.gl domain
+type HttpURLResolver struct {
+	c *http.Client
+}
+
+func (r HttpURLResolver) Resolve(shortUrl string, ctx context.Context) (string, error) {
+	current := shortUrl
+	for i := 0; i < 10; i++ {
+		parsed, err := url.Parse(current)
+		if err != nil {
+			return "", fmt.Errorf("invalid url: %w", err)
+		}
+		if !isShortShareHost(parsed.Host) {
+			return current, nil
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, current, nil)
+		if err != nil {
+			return "", fmt.Errorf("cannot prepare request: %w", err)
+		}
+		res, err := r.c.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("cannot fetch url: %w", err)
+		}
+		_ = res.Body.Close()
+		location, err := res.Location()
+		if err != nil {
+			return "", fmt.Errorf("url '%s' didn't redirect (status %d)", current, res.StatusCode)
+		}
+		current = location.String()
+	}
+	return "", errors.New("too many redirects")
+}
+
+// Creates resolver using copy of client that doesn't follow redirects automatically. http.DefaultClient is used
+// when client is nil
+func NewHttpURLResolver(client *http.Client) HttpURLResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirectClient := *client
+	noRedirectClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return HttpURLResolver{
+		c: &noRedirectClient,
+	}
+}
+
+func isShortShareHost(host string) bool {
+	return host == "photos.app.goo.gl" || host == "goo.gl"
+}
+
+// URLs copied without scheme
+func hasShareHostPrefix(value string) bool {
+	for _, prefix := range []string{"photos.app.goo.gl/", "goo.gl/", "photos.google.com/"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Extracts share token from shareable URL. Short URLs are resolved with resolver first. Values that are not URLs
+// (with or without scheme) are treated as share tokens. Full URLs are expected in
+// https://photos.google.com/share/<token>?key=<key> form.
+//
+// API doesn't document how shareable URLs relate to share tokens. Path segment after /share/ is used as token and key
+// parameter (access key for browsers) is ignored, wrong token makes Join fail with API error. Prefer
+// AlbumShareInfo.ShareToken when it's available
+func ParseShareToken(shareUrl string, resolver URLResolver, ctx context.Context) (string, error) {
+	shareUrl = strings.TrimSpace(shareUrl)
+	if !strings.Contains(shareUrl, "://") && hasShareHostPrefix(shareUrl) {
+		shareUrl = "https://" + shareUrl
+	}
+	if !strings.Contains(shareUrl, "://") {
+		if shareUrl == "" {
+			return "", errors.New("empty share url")
+		}
+		return shareUrl, nil
+	}
+	parsed, err := url.Parse(shareUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid share url: %w", err)
+	}
+	if isShortShareHost(parsed.Host) {
+		if resolver == nil {
+			return "", errors.New("resolver is required for short share urls")
+		}
+		resolved, err := resolver.Resolve(shareUrl, ctx)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve share url: %w", err)
+		}
+		parsed, err = url.Parse(resolved)
+		if err != nil {
+			return "", fmt.Errorf("invalid resolved share url: %w", err)
+		}
+	}
+	if parsed.Host != "photos.google.com" {
+		return "", fmt.Errorf("unsupported share url host '%s'", parsed.Host)
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "share" && segments[i+1] != "" {
+			return segments[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("share url '%s' doesn't contain share token", parsed.String())
+}
+
+// Result of joining single album
+type JoinResult struct {
+	// Share URL or token passed to JoinAll
+	ShareUrl   string
+	ShareToken string
+	// Nil when joining failed
+	Album *albums.Album
+	Err   error
+}
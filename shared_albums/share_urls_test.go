@@ -0,0 +1,85 @@
+package shared_albums
+
+import (
+	"context"
+	"errors"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"net/http"
+	"testing"
+)
+
+type stubResolver map[string]string
+
+func (r stubResolver) Resolve(shortUrl string, ctx context.Context) (string, error) {
+	resolved, ok := r[shortUrl]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return resolved, nil
+}
+
+func TestParseShareToken(t *testing.T) {
+	resolver := stubResolver{
+		"https://photos.app.goo.gl/short": "https://photos.google.com/share/AF1QipToken?key=browserKey",
+	}
+	tests := []struct {
+		name     string
+		shareUrl string
+		expected string
+		wantErr  bool
+	}{
+		{"token", "AF1QipToken", "AF1QipToken", false},
+		{"token with whitespace", " AF1QipToken\n", "AF1QipToken", false},
+		{"empty", " ", "", true},
+		{"full url", "https://photos.google.com/share/AF1QipToken?key=browserKey", "AF1QipToken", false},
+		{"full url with user prefix", "https://photos.google.com/u/1/share/AF1QipToken", "AF1QipToken", false},
+		{"full url without scheme", "photos.google.com/share/AF1QipToken?key=browserKey", "AF1QipToken", false},
+		{"short url", "https://photos.app.goo.gl/short", "AF1QipToken", false},
+		{"short url without scheme", "photos.app.goo.gl/short", "AF1QipToken", false},
+		{"unresolvable short url", "https://photos.app.goo.gl/missing", "", true},
+		{"url without token", "https://photos.google.com/albums", "", true},
+		{"unsupported host", "https://example.com/share/AF1QipToken", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseShareToken(tt.shareUrl, resolver, context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+	t.Run("short url without resolver", func(t *testing.T) {
+		_, err := ParseShareToken("https://photos.app.goo.gl/short", nil, context.Background())
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestHttpURLResolver(t *testing.T) {
+	// short link redirects once more before landing on share page
+	client := test_utils.NewClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "photos.app.goo.gl/short":
+			w.Header().Set("Location", "https://goo.gl/next")
+			w.WriteHeader(http.StatusFound)
+		case "goo.gl/next":
+			w.Header().Set("Location", "https://photos.google.com/share/AF1QipToken?key=browserKey")
+			w.WriteHeader(http.StatusFound)
+		default:
+			t.Errorf("unexpected request %s%s", r.Host, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	resolver := NewHttpURLResolver(client)
+	resolved, err := resolver.Resolve("https://photos.app.goo.gl/short", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved != "https://photos.google.com/share/AF1QipToken?key=browserKey" {
+		t.Errorf("unexpected resolved url %s", resolved)
+	}
+}
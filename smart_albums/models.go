@@ -0,0 +1,31 @@
+package smart_albums
+
+import (
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"time"
+)
+
+// Smart album is a saved search materialized as a regular album
+type Definition struct {
+	// Unique name of smart album
+	Name string `json:"name"`
+	// Title of album created for smart album
+	Title   string                    `json:"title"`
+	Filters media_items.SearchFilters `json:"filters"`
+}
+
+// Result of last synchronization
+type State struct {
+	// ID of album created and owned by smart album
+	AlbumId         string    `json:"albumId,omitempty"`
+	LastRun         time.Time `json:"lastRun,omitempty"`
+	LastError       string    `json:"lastError,omitempty"`
+	MediaItemsCount int       `json:"mediaItemsCount"`
+	Added           int       `json:"added"`
+	Removed         int       `json:"removed"`
+}
+
+type SmartAlbum struct {
+	Definition Definition `json:"definition"`
+	State      State      `json:"state"`
+}
@@ -0,0 +1,181 @@
+package smart_albums
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"time"
+)
+
+// Keeps albums in sync with saved searches. Every smart album creates and owns its target album since only
+// app-created albums can be modified
+type Syncer struct {
+	albums     albums.AlbumsService
+	mediaItems media_items.MediaItemsService
+	store      Store
+}
+
+// Saves smart album definition. State of existing smart album (including its album) is kept, changed title is
+// applied to the album on next sync
+func (s Syncer) Define(definition Definition) error {
+	if definition.Name == "" {
+		return errors.New("smart album name is required")
+	}
+	if definition.Title == "" {
+		return errors.New("smart album title is required")
+	}
+	smartAlbum, err := s.store.Get(definition.Name)
+	if err != nil {
+		return fmt.Errorf("cannot get smart album: %w", err)
+	}
+	if smartAlbum == nil {
+		smartAlbum = &SmartAlbum{}
+	}
+	smartAlbum.Definition = definition
+	err = s.store.Save(*smartAlbum)
+	if err != nil {
+		return fmt.Errorf("cannot save smart album: %w", err)
+	}
+	return nil
+}
+
+// Runs search of smart album specified by name and updates its album to contain exactly found media items.
+// Album is created on first run and re-created when it was deleted. Result of run is stored in smart album state
+func (s Syncer) Sync(name string, ctx context.Context) (*State, error) {
+	smartAlbum, err := s.store.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get smart album: %w", err)
+	}
+	if smartAlbum == nil {
+		return nil, fmt.Errorf("smart album '%s' doesn't exist", name)
+	}
+	syncErr := s.sync(smartAlbum, ctx)
+	smartAlbum.State.LastRun = time.Now()
+	smartAlbum.State.LastError = ""
+	if syncErr != nil {
+		smartAlbum.State.LastError = syncErr.Error()
+	}
+	err = s.store.Save(*smartAlbum)
+	if err != nil {
+		return nil, fmt.Errorf("cannot save smart album state: %w", err)
+	}
+	if syncErr != nil {
+		return &smartAlbum.State, fmt.Errorf("cannot sync smart album '%s': %w", name, syncErr)
+	}
+	return &smartAlbum.State, nil
+}
+
+func (s Syncer) sync(smartAlbum *SmartAlbum, ctx context.Context) error {
+	if smartAlbum.State.AlbumId != "" {
+		album, err := s.albums.Get(smartAlbum.State.AlbumId, ctx)
+		switch {
+		case internal.IsNotFoundError(err):
+			smartAlbum.State.AlbumId = ""
+		case err != nil:
+			return fmt.Errorf("cannot get album: %w", err)
+		case album.Title != smartAlbum.Definition.Title:
+			_, err = s.albums.Patch(albums.Album{
+				ID:    album.ID,
+				Title: smartAlbum.Definition.Title,
+			}, []albums.Field{albums.AlbumFieldTitle}, ctx)
+			if err != nil {
+				return fmt.Errorf("cannot rename album: %w", err)
+			}
+		}
+	}
+	if smartAlbum.State.AlbumId == "" {
+		album, err := s.albums.Create(smartAlbum.Definition.Title, ctx)
+		if err != nil {
+			return fmt.Errorf("cannot create album: %w", err)
+		}
+		smartAlbum.State.AlbumId = album.ID
+		// album ID is saved right away so failure below doesn't create another album on next run
+		err = s.store.Save(*smartAlbum)
+		if err != nil {
+			return fmt.Errorf("cannot save smart album state: %w", err)
+		}
+	}
+	items, err := s.mediaItems.SearchAll(&media_items.SearchOptions{
+		PageSize: 100,
+		Filters:  &smartAlbum.Definition.Filters,
+	}, ctx)
+	if err != nil {
+		return fmt.Errorf("cannot search media items: %w", err)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	result, err := s.albums.Reconcile(smartAlbum.State.AlbumId, ids, nil, ctx)
+	if result != nil {
+		smartAlbum.State.Added = 0
+		smartAlbum.State.Removed = 0
+		if result.Added != nil {
+			smartAlbum.State.Added = len(result.Added.Applied)
+		}
+		if result.Removed != nil {
+			smartAlbum.State.Removed = len(result.Removed.Applied)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot update album: %w", err)
+	}
+	smartAlbum.State.MediaItemsCount = len(ids)
+	return nil
+}
+
+// Syncs all stored smart albums. Failure of one smart album doesn't stop others - it's recorded in its state
+// and returned error contains number of failed smart albums
+func (s Syncer) SyncAll(ctx context.Context) error {
+	smartAlbums, err := s.store.List()
+	if err != nil {
+		return fmt.Errorf("cannot list smart albums: %w", err)
+	}
+	failed := 0
+	var firstErr error
+	for _, smartAlbum := range smartAlbums {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		_, err = s.Sync(smartAlbum.Definition.Name, ctx)
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d smart albums failed: %w", failed, len(smartAlbums), firstErr)
+	}
+	return nil
+}
+
+// Runs SyncAll immediately and then every interval until context is done. Sync errors are passed to onError
+// callback (if specified) and don't stop the loop
+func (s Syncer) Run(interval time.Duration, onError func(err error), ctx context.Context) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := s.SyncAll(ctx)
+		if err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewSyncer(albumsService albums.AlbumsService, mediaItemsService media_items.MediaItemsService, store Store) Syncer {
+	return Syncer{
+		albums:     albumsService,
+		mediaItems: mediaItemsService,
+		store:      store,
+	}
+}
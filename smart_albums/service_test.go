@@ -0,0 +1,228 @@
+package smart_albums
+
+import (
+	"context"
+	"github.com/duffpl/google-photos-api-client/albums"
+	"github.com/duffpl/google-photos-api-client/internal/test_utils"
+	"github.com/duffpl/google-photos-api-client/media_items"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func landscapes(name string, title string) Definition {
+	return Definition{
+		Name:  name,
+		Title: title,
+		Filters: media_items.SearchFilters{
+			ContentFilter: &media_items.ContentFilter{
+				IncludedContentCategories: []media_items.ContentCategory{media_items.ContentCategoryLandscapes},
+			},
+		},
+	}
+}
+
+func newTestSyncer(t *testing.T) (Syncer, *test_utils.Library) {
+	library := test_utils.NewLibrary(t)
+	library.AddMediaItems(
+		test_utils.LibraryMediaItem{ID: "mountains", Categories: []string{"LANDSCAPES"}},
+		test_utils.LibraryMediaItem{ID: "lake", Categories: []string{"LANDSCAPES"}},
+		test_utils.LibraryMediaItem{ID: "cat", Categories: []string{"ANIMALS"}},
+	)
+	client := test_utils.NewClient(t, library)
+	store := NewFileStore(filepath.Join(t.TempDir(), "smart_albums.json"))
+	return NewSyncer(albums.NewHttpAlbumsService(client), media_items.NewHttpMediaItemsService(client, nil), store), library
+}
+
+func TestSyncerSync(t *testing.T) {
+	tests := []struct {
+		name string
+		// changes library after first sync
+		change          func(library *test_utils.Library)
+		expectedItems   []string
+		expectedAdded   int
+		expectedRemoved int
+	}{
+		{"unchanged", func(*test_utils.Library) {}, []string{"mountains", "lake"}, 0, 0},
+		{"item matches search", func(library *test_utils.Library) {
+			library.UpdateMediaItem(test_utils.LibraryMediaItem{ID: "cat", Categories: []string{"ANIMALS", "LANDSCAPES"}})
+		}, []string{"mountains", "lake", "cat"}, 1, 0},
+		{"item no longer matches search", func(library *test_utils.Library) {
+			library.UpdateMediaItem(test_utils.LibraryMediaItem{ID: "lake"})
+		}, []string{"mountains"}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer, library := newTestSyncer(t)
+			err := syncer.Define(landscapes("landscapes", "Landscapes"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			state, err := syncer.Sync("landscapes", context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state.AlbumId == "" || state.Added != 2 || state.Removed != 0 || state.MediaItemsCount != 2 {
+				t.Fatalf("unexpected state after first sync: %+v", state)
+			}
+			tt.change(library)
+			state, err = syncer.Sync("landscapes", context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state.Added != tt.expectedAdded || state.Removed != tt.expectedRemoved {
+				t.Errorf("expected %d added and %d removed, got %+v", tt.expectedAdded, tt.expectedRemoved, state)
+			}
+			album := library.Album(state.AlbumId)
+			if !reflect.DeepEqual(album.MediaItemIds, tt.expectedItems) {
+				t.Errorf("expected album items %v, got %v", tt.expectedItems, album.MediaItemIds)
+			}
+			if got := library.CountRequests("POST", "/v1/albums"); got != 1 {
+				t.Errorf("expected album to be created once, got %d", got)
+			}
+		})
+	}
+}
+
+func TestSyncerSyncRecreatesDeletedAlbum(t *testing.T) {
+	syncer, library := newTestSyncer(t)
+	_ = syncer.Define(landscapes("landscapes", "Landscapes"))
+	state, err := syncer.Sync("landscapes", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deletedId := state.AlbumId
+	library.RemoveAlbum(deletedId)
+	state, err = syncer.Sync("landscapes", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.AlbumId == deletedId || state.Added != 2 {
+		t.Errorf("expected new album with all items, got %+v", state)
+	}
+	if album := library.Album(state.AlbumId); album == nil || album.Title != "Landscapes" {
+		t.Errorf("expected album to be re-created, got %+v", album)
+	}
+}
+
+func TestSyncerSyncRenamesAlbum(t *testing.T) {
+	syncer, library := newTestSyncer(t)
+	_ = syncer.Define(landscapes("landscapes", "Landscapes"))
+	state, err := syncer.Sync("landscapes", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = syncer.Define(landscapes("landscapes", "Mountains and lakes"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	renamed, err := syncer.Sync("landscapes", context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renamed.AlbumId != state.AlbumId {
+		t.Errorf("expected album to be kept, got %s", renamed.AlbumId)
+	}
+	if album := library.Album(state.AlbumId); album.Title != "Mountains and lakes" {
+		t.Errorf("expected album to be renamed, got %q", album.Title)
+	}
+	_, _ = syncer.Sync("landscapes", context.Background())
+	if got := library.CountRequests("PATCH", "/v1/albums/"+state.AlbumId); got != 1 {
+		t.Errorf("expected album to be patched once, got %d", got)
+	}
+}
+
+func TestSyncerSyncAll(t *testing.T) {
+	syncer, library := newTestSyncer(t)
+	_ = syncer.Define(landscapes("landscapes", "Landscapes"))
+	_ = syncer.Define(landscapes("broken", "Broken"))
+	_ = syncer.Define(Definition{
+		Name:  "animals",
+		Title: "Animals",
+		Filters: media_items.SearchFilters{
+			ContentFilter: &media_items.ContentFilter{
+				IncludedContentCategories: []media_items.ContentCategory{media_items.ContentCategoryAnimals},
+			},
+		},
+	})
+	err := syncer.SyncAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	broken, _ := syncer.store.Get("broken")
+	library.RemoveAlbum(broken.State.AlbumId)
+	library.Fail("POST", "/v1/albums", 500, "INTERNAL")
+	err = syncer.SyncAll(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "1 of 3 smart albums failed") {
+		t.Fatalf("expected single failure, got %v", err)
+	}
+	smartAlbums, err := syncer.store.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := make([]string, 0)
+	for _, smartAlbum := range smartAlbums {
+		names = append(names, smartAlbum.Definition.Name)
+		if (smartAlbum.State.LastError != "") != (smartAlbum.Definition.Name == "broken") {
+			t.Errorf("%s: unexpected last error %q", smartAlbum.Definition.Name, smartAlbum.State.LastError)
+		}
+		if smartAlbum.State.LastRun.IsZero() {
+			t.Errorf("%s: last run wasn't recorded", smartAlbum.Definition.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"animals", "broken", "landscapes"}) {
+		t.Errorf("expected smart albums ordered by name, got %v", names)
+	}
+}
+
+func TestSyncerDefine(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition Definition
+		wantErr    bool
+	}{
+		{"valid", landscapes("landscapes", "Landscapes"), false},
+		{"missing name", landscapes("", "Landscapes"), true},
+		{"missing title", landscapes("landscapes", ""), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncer, _ := newTestSyncer(t)
+			err := syncer.Define(tt.definition)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "smart_albums.json")
+	store := NewFileStore(path)
+	missing, err := store.Get("landscapes")
+	if err != nil || missing != nil {
+		t.Fatalf("expected nothing for missing file, got %v, %v", missing, err)
+	}
+	smartAlbum := SmartAlbum{Definition: landscapes("landscapes", "Landscapes"), State: State{AlbumId: "album", Added: 2}}
+	err = store.Save(smartAlbum)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = store.Save(SmartAlbum{Definition: landscapes("other", "Other")})
+	loaded, err := NewFileStore(path).Get("landscapes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(*loaded, smartAlbum) {
+		t.Errorf("expected %+v, got %+v", smartAlbum, *loaded)
+	}
+	err = store.Delete("landscapes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	smartAlbums, _ := NewFileStore(path).List()
+	if len(smartAlbums) != 1 || smartAlbums[0].Definition.Name != "other" {
+		t.Errorf("expected only other smart album to be left, got %+v", smartAlbums)
+	}
+}
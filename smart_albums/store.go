@@ -0,0 +1,87 @@
+package smart_albums
+
+import (
+	"fmt"
+	"github.com/duffpl/google-photos-api-client/internal"
+	"sort"
+)
+
+// Persists smart album definitions together with their state
+type Store interface {
+	List() ([]SmartAlbum, error)
+	Get(name string) (*SmartAlbum, error)
+	Save(smartAlbum SmartAlbum) error
+	Delete(name string) error
+}
+
+// Store keeping all smart albums in single JSON file
+type FileStore struct {
+	file internal.JSONFileStore
+}
+
+func (s FileStore) load() (map[string]SmartAlbum, error) {
+	result := make(map[string]SmartAlbum)
+	err := s.file.Load(&result)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load smart albums: %w", err)
+	}
+	return result, nil
+}
+
+func (s FileStore) update(fn func(smartAlbums map[string]SmartAlbum)) error {
+	smartAlbums := make(map[string]SmartAlbum)
+	err := s.file.Update(&smartAlbums, func() {
+		fn(smartAlbums)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save smart albums: %w", err)
+	}
+	return nil
+}
+
+// Lists smart albums ordered by name
+func (s FileStore) List() ([]SmartAlbum, error) {
+	smartAlbums, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SmartAlbum, 0, len(smartAlbums))
+	for _, smartAlbum := range smartAlbums {
+		result = append(result, smartAlbum)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Definition.Name < result[j].Definition.Name
+	})
+	return result, nil
+}
+
+// Returns smart album specified by name or nil when it doesn't exist
+func (s FileStore) Get(name string) (*SmartAlbum, error) {
+	smartAlbums, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	smartAlbum, ok := smartAlbums[name]
+	if !ok {
+		return nil, nil
+	}
+	return &smartAlbum, nil
+}
+
+func (s FileStore) Save(smartAlbum SmartAlbum) error {
+	return s.update(func(smartAlbums map[string]SmartAlbum) {
+		smartAlbums[smartAlbum.Definition.Name] = smartAlbum
+	})
+}
+
+func (s FileStore) Delete(name string) error {
+	return s.update(func(smartAlbums map[string]SmartAlbum) {
+		delete(smartAlbums, name)
+	})
+}
+
+func NewFileStore(path string) FileStore {
+	return FileStore{
+		file: internal.NewJSONFileStore(path),
+	}
+}
@@ -3,6 +3,8 @@ package uploader
 import (
 	"context"
 	"fmt"
+	"github.com/duffpl/google-photos-api-client/call_options"
+	"github.com/duffpl/google-photos-api-client/client_options"
 	"github.com/duffpl/google-photos-api-client/internal"
 	"github.com/gabriel-vasile/mimetype"
 	"net/http"
@@ -11,21 +13,23 @@ import (
 )
 
 type MediaUploader interface {
-	UploadFile(filePath string, ctx context.Context) (string, error)
+	UploadFile(filePath string, ctx context.Context, opts ...call_options.CallOption) (string, error)
 }
 
 type HttpMediaUploader struct {
 	client *internal.HttpClient
 }
 
-// Uploads file specified by path. Returns upload token
+// Uploads file specified by path. Returns upload token. Upload is never retried since file body cannot be re-read,
+// fields call option is ignored
 //
 // Doc: https://developers.google.com/photos/library/guides/upload-media
-func (h HttpMediaUploader) UploadFile(filePath string, ctx context.Context) (string, error) {
+func (h HttpMediaUploader) UploadFile(filePath string, ctx context.Context, opts ...call_options.CallOption) (string, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("cannot open file: %w", err)
 	}
+	defer f.Close()
 	mimeType, err := mimetype.DetectFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("cannot detect mime type: %w", err)
@@ -36,15 +40,15 @@ func (h HttpMediaUploader) UploadFile(filePath string, ctx context.Context) (str
 		req.Header.Set("X-Goog-Upload-File-Name", path.Base(filePath))
 		req.Header.Set("X-Goog-Upload-Content-Type", mimeType.String())
 		req.Header.Set("X-Goog-Upload-Protocol", "raw")
-	}, ctx)
+	}, ctx, internal.WithoutFields(opts)...)
 	if err != nil {
 		return "", fmt.Errorf("request failed: %w", err)
 	}
 	return token, nil
 }
 
-func NewHttpMediaUploader(authenticatedClient *http.Client) HttpMediaUploader {
+func NewHttpMediaUploader(authenticatedClient *http.Client, options ...client_options.ClientOption) HttpMediaUploader {
 	return HttpMediaUploader{
-		client: internal.NewHttpClient(authenticatedClient),
+		client: internal.NewHttpClient(authenticatedClient, options...),
 	}
 }